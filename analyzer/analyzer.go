@@ -0,0 +1,84 @@
+// Package analyzer provides go-unexport in a form of go/analysis analyzer.
+//
+// Unlike the go-unexport command, the analyzer never modifies the code,
+// it only reports the symbols that could be unexported.
+package analyzer
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/quasilyte/go-unexport/internal/refs"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// Analyzer is a main package checker: it reports exported symbols
+// of main packages that are not used outside of their package.
+//
+// Analysis drivers process one package at a time, so the importers of a
+// library package are never known. This is why only main packages are
+// checked: nothing can import them, so their analysis is complete.
+// Other packages are skipped without any reports.
+// Use go-unexport command to analyze library packages.
+var Analyzer = &analysis.Analyzer{
+	Name: "unexport",
+	Doc: "reports exported symbols of main packages that are not used outside of their package\n\n" +
+		"Only main packages are checked: a library package importers are not visible to the analyzer.",
+	Run: run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if pass.Pkg.Name() != "main" {
+		return nil, nil
+	}
+
+	pkg := &packages.Package{
+		ID:        pass.Pkg.Path(),
+		Name:      pass.Pkg.Name(),
+		PkgPath:   pass.Pkg.Path(),
+		Fset:      pass.Fset,
+		Syntax:    pass.Files,
+		Types:     pass.Pkg,
+		TypesInfo: pass.TypesInfo,
+	}
+	idx := refs.NewIndex(pass.Fset, []*packages.Package{pkg})
+
+	for _, f := range pass.Files {
+		if strings.HasSuffix(pass.Fset.Position(f.Pos()).Filename, "_test.go") {
+			continue
+		}
+		for _, id := range declNames(f) {
+			if !id.IsExported() {
+				continue
+			}
+			obj := pass.TypesInfo.Defs[id]
+			if obj == nil || len(idx.External(obj)) != 0 || idx.RequiredByInterface(obj) {
+				continue
+			}
+			pass.Reportf(id.Pos(), "%s is not used outside of its package, consider unexporting it", id.Name)
+		}
+	}
+
+	return nil, nil
+}
+
+func declNames(f *ast.File) []*ast.Ident {
+	var names []*ast.Ident
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					names = append(names, spec.Names...)
+				case *ast.TypeSpec:
+					names = append(names, spec.Name)
+				}
+			}
+		case *ast.FuncDecl:
+			names = append(names, decl.Name)
+		}
+	}
+	return names
+}
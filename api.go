@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+)
+
+// apiKinds lists the -api-report columns.
+var apiKinds = []string{"func", "type", "method", "var", "const", "field"}
+
+// countAPI adds exported sym to its package API stats.
+// Exported fields of struct types are counted as well.
+func (l *linter) countAPI(sym *symbol) {
+	counts := l.api[sym.pkg.PkgPath]
+	if counts == nil {
+		counts = make(map[string]int)
+		l.api[sym.pkg.PkgPath] = counts
+	}
+	counts[sym.kind]++
+
+	spec, ok := sym.spec.(*ast.TypeSpec)
+	if !ok {
+		return
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.Fields.List {
+		for _, id := range fieldNames(field) {
+			if id.IsExported() {
+				counts["field"]++
+			}
+		}
+	}
+}
+
+// fieldNames returns field names; the embedded field name is its type name.
+func fieldNames(field *ast.Field) []*ast.Ident {
+	if len(field.Names) != 0 {
+		return field.Names
+	}
+	typ := field.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch typ := typ.(type) {
+	case *ast.Ident:
+		return []*ast.Ident{typ}
+	case *ast.SelectorExpr:
+		return []*ast.Ident{typ.Sel}
+	default:
+		return nil
+	}
+}
+
+func (l *linter) printAPIReport() error {
+	if !l.flags.apiReport {
+		return nil
+	}
+
+	paths := make([]string, 0, len(l.api))
+	for path := range l.api {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("%s:\n", path)
+		for _, kind := range apiKinds {
+			fmt.Printf("\t%-8s %d\n", kind+"s:", l.api[path][kind])
+		}
+	}
+
+	return errDone
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// updateAPIBaseline regenerates the apidiff baseline of every package
+// that was changed, so the apidiff compatibility check accepts the
+// removed symbols intentionally.
+//
+// The baseline is the apidiff export data file, written by:
+//
+//	apidiff -w file pkgpath
+//
+// If -preserve-api-file contains {pkg}, it's replaced by the package path
+// with all slashes replaced by underscores, so every package gets its own
+// file; otherwise only one package is allowed to be changed.
+func (l *linter) updateAPIBaseline() error {
+	if l.flags.preserveAPIFile == "" || l.flags.edits != "" {
+		return nil
+	}
+
+	var paths []string
+	for path := range l.changed {
+		if !strings.HasSuffix(path, "_test") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	if len(paths) > 1 && !strings.Contains(l.flags.preserveAPIFile, "{pkg}") {
+		return fmt.Errorf("%d packages changed, use {pkg} in the file name to write a baseline per package", len(paths))
+	}
+
+	for _, path := range paths {
+		filename := strings.ReplaceAll(l.flags.preserveAPIFile, "{pkg}", strings.ReplaceAll(path, "/", "_"))
+		out, err := exec.Command("apidiff", "-w", filename, path).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s: %v: %s", path, err, out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// auditRecord is a single -audit-log line.
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Run      string    `json:"run"`
+	Package  string    `json:"package"`
+	Position string    `json:"position"`
+	Kind     string    `json:"kind,omitempty"`
+	Old      string    `json:"old"`
+	New      string    `json:"new,omitempty"`
+	Deleted  bool      `json:"deleted,omitempty"`
+
+	// Recv is the receiver type name of a method.
+	Recv string `json:"recv,omitempty"`
+
+	// Undo is the run reverted by this record, see undoRenames.
+	Undo string `json:"undo,omitempty"`
+}
+
+func (l *linter) addAuditRecord(sym *symbol, newName string) {
+	if l.flags.auditLog == "" {
+		return
+	}
+	if l.auditRun == "" {
+		l.auditRun = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	l.audit = append(l.audit, auditRecord{
+		Time:     time.Now().UTC(),
+		User:     os.Getenv("USER"),
+		Run:      l.auditRun,
+		Package:  sym.pkg.PkgPath,
+		Position: l.position(sym.ident.Pos()).String(),
+		Kind:     sym.kind,
+		Old:      sym.ident.Name,
+		New:      newName,
+		Deleted:  newName == "",
+		Recv:     symRecvName(sym),
+	})
+}
+
+// symRecvName returns sym receiver type name,
+// or an empty string if sym is not a method.
+func symRecvName(sym *symbol) string {
+	if decl, ok := sym.decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+		return recvTypeName(decl.Recv.List[0].Type)
+	}
+	return ""
+}
+
+// writeAuditLog appends the run records to the -audit-log file.
+// The file is never truncated. Nothing is written with -edits,
+// as no changes are applied.
+func (l *linter) writeAuditLog() error {
+	if l.flags.auditLog == "" || l.flags.edits != "" || len(l.audit) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(l.flags.auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, r := range l.audit {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// readUndoLog finds the -audit-log records of the last run
+// that is not reverted yet. Unless the targets are given,
+// the packages of the records are loaded.
+func (l *linter) readUndoLog() error {
+	if l.verb != "undo" {
+		return nil
+	}
+
+	f, err := os.Open(l.flags.auditLog)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var records []auditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return fmt.Errorf("%s: %v", l.flags.auditLog, err)
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// Records of the older versions have no run,
+	// they are not reverted.
+	undone := make(map[string]bool)
+	for _, r := range records {
+		undone[r.Undo] = true
+	}
+	var run string
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if r.Run != "" && r.Undo == "" && !undone[r.Run] {
+			run = r.Run
+			break
+		}
+	}
+	if run == "" {
+		return errors.New("no runs to undo in " + l.flags.auditLog)
+	}
+
+	// Reverted in the reverse order, so the renames that
+	// depended on the earlier ones are reverted first.
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Run == run {
+			l.undo = append(l.undo, records[i])
+		}
+	}
+	if len(l.flags.targets) == 0 {
+		for _, r := range l.undo {
+			pkgPath := strings.TrimSuffix(r.Package, "_test")
+			if !slices.Contains(l.flags.targets, pkgPath) {
+				l.flags.targets = append(l.flags.targets, pkgPath)
+			}
+		}
+	}
+	return nil
+}
+
+// undoRenames renames the symbols of the readUndoLog records back.
+//
+// It's a separate mode, like normalizeReceivers. Deleted symbols
+// can't be restored, and the references that breaking renames left
+// broken in other packages are not updated: they don't type check.
+func (l *linter) undoRenames() error {
+	if l.verb != "undo" {
+		return nil
+	}
+
+	run := l.undo[0].Run
+	for _, r := range l.undo {
+		status := "success"
+		sym := l.findRenamed(r)
+		switch {
+		case r.Deleted:
+			status = "impossible: deleted symbols can't be restored"
+		case sym == nil:
+			status = "impossible: not found at " + r.Position
+		default:
+			if err := l.renameInProcess(sym, r.Old); err != nil {
+				status = "impossible: " + err.Error()
+				break
+			}
+			l.addAuditRecord(sym, r.Old)
+			l.audit[len(l.audit)-1].Undo = run
+		}
+		newName := r.New
+		if r.Deleted {
+			newName = "<deleted>"
+		}
+		fmt.Printf("reverting %s -> %s... (%s)\n", r.Old, newName, status)
+	}
+
+	if err := l.applyEdits(); err != nil {
+		return err
+	}
+	if err := l.writeAuditLog(); err != nil {
+		return err
+	}
+	return errDone
+}
+
+// findRenamed returns the symbol that r renamed.
+//
+// The symbol is matched by its package, kind, name and receiver type:
+// the recorded position can be shifted by the other edits of the run,
+// like the deleted declarations. The receiver type could be renamed
+// by the same run, the files still have its new name.
+// The records without a kind are matched by their position,
+// that can be relative, see -relative-paths.
+func (l *linter) findRenamed(r auditRecord) *symbol {
+	recv := r.Recv
+	for _, other := range l.undo {
+		if recv != "" && other.Kind == "type" && other.Package == r.Package && other.Old == recv && !other.Deleted {
+			recv = other.New
+		}
+	}
+	for _, sym := range l.symbols {
+		if sym.obj == nil || sym.ident.Name != r.New || sym.pkg.PkgPath != r.Package {
+			continue
+		}
+		if r.Kind == "" {
+			if strings.HasSuffix(l.fset.Position(sym.ident.Pos()).String(), r.Position) {
+				return sym
+			}
+			continue
+		}
+		if sym.kind == r.Kind && symRecvName(sym) == recv {
+			return sym
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchSizes are the synthetic module sizes: packages x funcs per package.
+var benchSizes = []struct{ pkgs, funcs int }{
+	{10, 10},
+	{50, 20},
+}
+
+// writeSynthModule generates a module with pkgs packages
+// of funcs exported funcs each in dir. Every package calls
+// the first func of the previous one, so the other funcs
+// can be unexported and the first one can't.
+func writeSynthModule(tb testing.TB, dir string, pkgs, funcs int) {
+	tb.Helper()
+	files := map[string]string{
+		"go.mod": "module example.com/synth\n\ngo 1.21\n",
+	}
+	for i := 0; i < pkgs; i++ {
+		var src strings.Builder
+		fmt.Fprintf(&src, "package p%d\n\n", i)
+		if i != 0 {
+			fmt.Fprintf(&src, "import \"example.com/synth/p%d\"\n\n", i-1)
+		}
+		for j := 0; j < funcs; j++ {
+			fmt.Fprintf(&src, "func Func%d() int {\n", j)
+			switch {
+			case j != 0:
+				fmt.Fprintf(&src, "\treturn Func%d() + %d\n", j-1, j)
+			case i != 0:
+				fmt.Fprintf(&src, "\treturn p%d.Func0()\n", i-1)
+			default:
+				fmt.Fprintf(&src, "\treturn 0\n")
+			}
+			src.WriteString("}\n\n")
+		}
+		files[fmt.Sprintf("p%d/p.go", i)] = src.String()
+	}
+	for name, src := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// newBenchLinter returns a linter that is set up like
+// parseFlags does for "-renamer=<renamer> ./...",
+// running in a generated synthetic module.
+func newBenchLinter(b *testing.B, pkgs, funcs int) *linter {
+	b.Helper()
+	var l linter
+	l.init()
+	l.verb = "run"
+	l.flags.targets = []string{"./..."}
+	l.flags.renamer = "inprocess"
+	l.flags.examples = "block"
+	l.flags.keepLinknamed = true
+	l.toUnexported = toLowerFirst
+	l.unexport[""] = true
+
+	dir := b.TempDir()
+	writeSynthModule(b, dir, pkgs, funcs)
+	b.Chdir(dir)
+	b.Setenv("GOFLAGS", "")
+	b.Setenv("GOWORK", "off")
+	return &l
+}
+
+// quietStdout discards the tool output for the rest of the benchmark.
+func quietStdout(b *testing.B) {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		b.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	b.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+}
+
+func benchName(pkgs, funcs int) string {
+	return fmt.Sprintf("%dx%d", pkgs, funcs)
+}
+
+func BenchmarkLoad(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(benchName(size.pkgs, size.funcs), func(b *testing.B) {
+			l := newBenchLinter(b, size.pkgs, size.funcs)
+			for b.Loop() {
+				l.pkgs = nil
+				if err := l.loadTargets(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCollect(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(benchName(size.pkgs, size.funcs), func(b *testing.B) {
+			l := newBenchLinter(b, size.pkgs, size.funcs)
+			if err := l.loadTargets(); err != nil {
+				b.Fatal(err)
+			}
+			for b.Loop() {
+				l.symbols = nil
+				if err := l.indexReferences(); err != nil {
+					b.Fatal(err)
+				}
+				if err := l.collectSymbols(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRename measures the renames of a loaded module
+// with the in-process renamer. See BenchmarkRun for gorename.
+func BenchmarkRename(b *testing.B) {
+	quietStdout(b)
+	for _, size := range benchSizes {
+		b.Run(benchName(size.pkgs, size.funcs), func(b *testing.B) {
+			for b.Loop() {
+				b.StopTimer()
+				l := newBenchLinter(b, size.pkgs, size.funcs)
+				for _, step := range []func() error{l.loadTargets, l.indexReferences, l.collectSymbols} {
+					if err := step(); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.StartTimer()
+				if err := l.unexportSymbols(); err != nil {
+					b.Fatal(err)
+				}
+				if err := l.applyEdits(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRun measures the whole tool run for every renamer backend,
+// including the in-process renamer with a different -concurrency value.
+func BenchmarkRun(b *testing.B) {
+	_, err := exec.LookPath("gorename")
+	hasGorename := err == nil
+	renamers := []struct {
+		name string
+		args []string
+	}{
+		{"inprocess", []string{"-renamer=inprocess"}},
+		{"gorename", []string{"-renamer=gorename"}},
+		{"inprocess-concurrency-4", []string{"-renamer=inprocess", "-concurrency=4"}},
+	}
+	for _, size := range benchSizes {
+		for _, r := range renamers {
+			b.Run(benchName(size.pkgs, size.funcs)+"/"+r.name, func(b *testing.B) {
+				if strings.HasPrefix(r.name, "gorename") && !hasGorename {
+					b.Skip("gorename is not installed")
+				}
+				for b.Loop() {
+					b.StopTimer()
+					dir := b.TempDir()
+					writeSynthModule(b, dir, size.pkgs, size.funcs)
+					b.StartTimer()
+					if out, err := runToolErr(b, dir, append(r.args, "./...")...); err != nil {
+						b.Fatalf("%v\n%s", err, out)
+					}
+				}
+			})
+		}
+	}
+}
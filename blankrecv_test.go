@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestBlankReceivers(t *testing.T) {
+	// The receiver type is resolved for the blank
+	// and unnamed receivers too.
+	dir := copyFixture(t, "blankrecv")
+	out := runTool(t, dir, "-impact", "U.Name", "./...")
+	wantLines(t, out,
+		"p/p.go:15:13: U.Name: referenced by 1 of 1 dependent packages",
+		"example.com/blankrecv/cmd/app: 1 refs",
+	)
+
+	out = runTool(t, dir, "-renamer=inprocess", "-dedupe-results", "-v", "./...")
+	wantLines(t, out,
+		"trying to unexport String... (impossible: would break interface assignability)",
+		"example.com/blankrecv/p method T.Name: Name -> name",
+		"example.com/blankrecv/p method U.Size: Size -> size",
+	)
+	unwantLines(t, out, "method U.Name:")
+	checkBuild(t, dir)
+}
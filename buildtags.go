@@ -0,0 +1,58 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// excludedFiles returns the base names of sym package files that are excluded by the
+// build constraints, but mention sym name. Such files are invisible to the
+// renamers, so renaming sym could break the build for other platforms.
+//
+// Only the names are matched: an unrelated local variable with
+// the same name makes this check fail too, but it's better to be
+// conservative here.
+func (l *linter) excludedFiles(sym *symbol) []string {
+	names, ok := l.excluded[sym.pkg.PkgPath]
+	if !ok {
+		names = l.scanExcludedFiles(sym)
+		l.excluded[sym.pkg.PkgPath] = names
+	}
+	return names[sym.ident.Name]
+}
+
+// scanExcludedFiles maps identifier names to the files
+// that are excluded by the build constraints.
+func (l *linter) scanExcludedFiles(sym *symbol) map[string][]string {
+	names := make(map[string][]string)
+	fset := token.NewFileSet()
+	for _, filename := range sym.pkg.IgnoredFiles {
+		if !strings.HasSuffix(filename, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filename, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		// Files like go:build ignore generators are not a part of the package.
+		if f.Name.Name != sym.pkg.Name && f.Name.Name != sym.pkg.Name+"_test" {
+			continue
+		}
+		seen := make(map[string]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && !seen[id.Name] {
+				seen[id.Name] = true
+				names[id.Name] = append(names[id.Name], filepath.Base(filename))
+			}
+			return true
+		})
+	}
+	for _, files := range names {
+		sort.Strings(files)
+	}
+	return names
+}
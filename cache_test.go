@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// c reaches a through b that is not loaded as a target:
+// editing a must still invalidate c cached references.
+func TestCacheInvalidatedByIndirectDependency(t *testing.T) {
+	dir := copyFixture(t, "refscache")
+	cacheDir := t.TempDir()
+	args := []string{"check", "-cache-dir", cacheDir, "./a", "./c"}
+
+	out, _ := runToolErr(t, dir, args...)
+	wantLines(t, out, "used by: example.com/fx/c")
+
+	filename := filepath.Join(dir, "a", "a.go")
+	src := readFile(t, dir, "a/a.go")
+	src = strings.Replace(src, "type T", "// T is a type.\ntype T", 1)
+	if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ = runToolErr(t, dir, args...)
+	wantLines(t, out, "used by: example.com/fx/c")
+	unwantLines(t, out, "Foo: feasible")
+}
+
+func TestFeasibilityCacheInputs(t *testing.T) {
+	dir := copyFixture(t, "dead")
+	cache := filepath.Join(t.TempDir(), "feasibility.json")
+	args := []string{"check", "-feasibility-cache", cache, "./..."}
+
+	out, _ := runToolErr(t, dir, args...)
+	wantLines(t, out, "Unused: feasible")
+
+	ignore := filepath.Join(dir, ".go-unexport-ignore")
+	if err := os.WriteFile(ignore, []byte("Unused*\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = runToolErr(t, dir, args...)
+	unwantLines(t, out, "Unused")
+
+	// Cache hit: the report is the same.
+	again, _ := runToolErr(t, dir, args...)
+	if again != out {
+		t.Errorf("cached report differs:\n%s\nvs\n%s", again, out)
+	}
+}
@@ -0,0 +1,25 @@
+// Command go-unexport-golangci is a golangci-lint plugin that
+// runs go-unexport analyzer, so its findings are reported
+// alongside the other linters results.
+//
+// Like the analyzer itself, it only checks main packages.
+//
+// Build it with the same Go and golang.org/x/tools versions
+// as golangci-lint itself:
+//
+//	go build -buildmode=plugin -o go-unexport.so ./cmd/go-unexport-golangci
+package main
+
+import (
+	"github.com/quasilyte/go-unexport/analyzer"
+	"golang.org/x/tools/go/analysis"
+)
+
+// New is the golangci-lint plugin entry point.
+func New(conf interface{}) ([]*analysis.Analyzer, error) {
+	return []*analysis.Analyzer{analyzer.Analyzer}, nil
+}
+
+// main is never called when built as a plugin,
+// it only makes the package buildable with go build ./...
+func main() {}
@@ -0,0 +1,15 @@
+// Command go-unexport-vet runs go-unexport analyzer.
+//
+// It can be used as a go vet tool:
+//
+//	go vet -vettool=$(which go-unexport-vet) ./...
+package main
+
+import (
+	"github.com/quasilyte/go-unexport/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollectUnexportList(t *testing.T) {
+	dir := copyFixture(t, "collect")
+	out := runTool(t, dir, "list", "-unexport", "A", "./...")
+	wantLines(t, out, "p/p.go:3:6:7:A:func")
+	unwantLines(t, out, ":B:", ":C:", ":T:")
+}
+
+func TestCollectSkipAll(t *testing.T) {
+	dir := copyFixture(t, "collect")
+	out := runTool(t, dir, "list", "-skip", "B", "./...")
+	wantLines(t, out,
+		"p/p.go:3:6:7:A:func",
+		"p/p.go:7:6:7:C:func",
+		"p/p.go:9:6:7:T:type",
+	)
+	unwantLines(t, out, ":B:")
+
+	out = runTool(t, dir, "-renamer=inprocess", "-skip", "B", "./...")
+	wantLines(t, out, "trying to unexport A... (success)")
+	unwantLines(t, out, "unexport B...")
+	checkBuild(t, dir)
+}
+
+func TestCollectNoSyntax(t *testing.T) {
+	// The ignored package has no files to load, it's reported with -v.
+	dir := copyFixture(t, "collect")
+	out := runTool(t, dir, "list", "-v", "./p", "./ignored")
+	wantLines(t, out,
+		"example.com/collect/ignored: no syntax files loaded, skipping",
+		"build constraints exclude all Go files in ",
+		"p/p.go:3:6:7:A:func",
+	)
+	out = runTool(t, dir, "list", "./p", "./ignored")
+	unwantLines(t, out, "no syntax files loaded")
+}
+
+func TestCollectOverlappingPatterns(t *testing.T) {
+	dir := copyFixture(t, "collect")
+	out := runTool(t, dir, "list", "./p", "./...", "example.com/collect/p")
+	if n := strings.Count(out, ":A:func"); n != 1 {
+		t.Errorf("A is listed %d times:\n%s", n, out)
+	}
+
+	out = runTool(t, dir, "-renamer=inprocess", "./p", "./...")
+	if n := strings.Count(out, "trying to unexport A..."); n != 1 {
+		t.Errorf("A is processed %d times:\n%s", n, out)
+	}
+	checkBuild(t, dir)
+}
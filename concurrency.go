@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// processConcurrently is like processing l.symbols one by one, but
+// several packages are processed at once. Symbols of the same package
+// are still processed sequentially; its external test package is a part
+// of the same unit, as they share the directory.
+//
+// The renames change the shared linter state, so they're done under
+// l.mu, but the expensive checks that only depend on the references
+// index are computed beforehand, without holding it.
+//
+// There are at most -concurrency packages in progress and at most
+// -max-concurrency-per-module of them belong to the same module.
+func (l *linter) processConcurrently() {
+	var order []string
+	byUnit := make(map[string][]*symbol)
+	for _, sym := range l.symbols {
+		unit := strings.TrimSuffix(sym.pkg.PkgPath, "_test")
+		if byUnit[unit] == nil {
+			order = append(order, unit)
+		}
+		byUnit[unit] = append(byUnit[unit], sym)
+	}
+
+	global := make(chan struct{}, l.flags.concurrency)
+	perModule := make(map[string]chan struct{})
+	var wg sync.WaitGroup
+	for _, unit := range order {
+		syms := byUnit[unit]
+		module := ""
+		if syms[0].pkg.Module != nil {
+			module = syms[0].pkg.Module.Path
+		}
+		moduleSem := perModule[module]
+		if moduleSem == nil {
+			moduleSem = make(chan struct{}, l.flags.concurrencyPerModule)
+			perModule[module] = moduleSem
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Acquire the module slot first, so the global
+			// slot is not wasted on waiting for it.
+			moduleSem <- struct{}{}
+			global <- struct{}{}
+			defer func() {
+				<-global
+				<-moduleSem
+			}()
+
+			for _, sym := range syms {
+				if sym.obj != nil && sym.ident.IsExported() {
+					required := l.refs.RequiredByInterface(sym.obj)
+					sym.requiredByInterface = &required
+				}
+			}
+
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, sym := range syms {
+				l.processSymbol(sym)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConcurrency(t *testing.T) {
+	dir := copyFixture(t, "concurrency")
+	out := runTool(t, dir, "-renamer=inprocess", "-concurrency=4", "-max-concurrency-per-module=2", "./...")
+	wantLines(t, out,
+		"trying to unexport Local... (success)",
+		"trying to unexport UsedByTest... (impossible: would break package clients)",
+		"trying to unexport UsedByB... (impossible: would break package clients)",
+		"trying to unexport Method... (success)",
+		"trying to unexport T... (success)",
+	)
+	if n := strings.Count(out, "unexport Local... (success)"); n != 3 {
+		t.Errorf("got %d successful Local renames, want 3:\n%s", n, out)
+	}
+	checkBuild(t, dir)
+}
+
+func TestConcurrencyGorename(t *testing.T) {
+	dir := copyFixture(t, "concurrency")
+	out, err := runToolErr(t, dir, "-renamer=gorename", "-concurrency=4", "./...")
+	if err == nil {
+		t.Fatalf("-concurrency with gorename is accepted:\n%s", out)
+	}
+	wantLines(t, out, "-concurrency requires -renamer=inprocess")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"go/ast"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/tools/cover"
+)
+
+func (l *linter) readCoverage() error {
+	if l.flags.coverage == "" {
+		return nil
+	}
+
+	profiles, err := cover.ParseProfiles(l.flags.coverage)
+	if err != nil {
+		return err
+	}
+	l.coverage = make(map[string]*cover.Profile, len(profiles))
+	for _, p := range profiles {
+		l.coverage[p.FileName] = p
+	}
+	return nil
+}
+
+// isCovered reports whether sym should be excluded from the candidates
+// due to the coverage profile: only funcs and methods that were never
+// executed are considered to be likely unused.
+//
+// Files that are not mentioned in the profile are treated as not covered.
+func (l *linter) isCovered(sym *symbol) bool {
+	if l.coverage == nil {
+		return false
+	}
+	decl, ok := sym.decl.(*ast.FuncDecl)
+	if !ok {
+		return true
+	}
+
+	// Profiles use import path based file names.
+	start := l.fset.Position(decl.Pos())
+	end := l.fset.Position(decl.End())
+	profile := l.coverage[path.Join(sym.pkg.PkgPath, filepath.Base(start.Filename))]
+	if profile == nil {
+		return false
+	}
+	for _, b := range profile.Blocks {
+		if b.Count == 0 {
+			continue
+		}
+		afterStart := b.StartLine > start.Line || (b.StartLine == start.Line && b.StartCol >= start.Column)
+		beforeEnd := b.EndLine < end.Line || (b.EndLine == end.Line && b.EndCol <= end.Column)
+		if afterStart && beforeEnd {
+			return true
+		}
+	}
+	return false
+}
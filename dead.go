@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// deleteDead removes sym declaration if it has no references at all,
+// except for the ones inside of the declaration itself.
+// It reports whether sym was deleted.
+//
+// Only the cases that are trivially safe to delete are handled:
+// the symbol should be the only name of its declaration and
+// removing it should not cause any side effects.
+func (l *linter) deleteDead(sym *symbol) bool {
+	if sym.obj == nil || len(l.usageRefs(sym)) != 0 || len(l.excludedFiles(sym)) != 0 || l.linknamedAt(sym) != "" {
+		return false
+	}
+	if strings.HasSuffix(l.fset.Position(sym.ident.Pos()).Filename, "_test.go") {
+		return false // Test funcs are never referenced
+	}
+
+	var node ast.Node
+	var doc *ast.CommentGroup
+	switch decl := sym.decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv != nil {
+			return false // Could be required to implement an interface
+		}
+		node, doc = decl, decl.Doc
+	case *ast.GenDecl:
+		if !canDeleteSpec(sym.pkg.TypesInfo, decl, sym.spec) {
+			return false
+		}
+		if len(decl.Specs) == 1 {
+			node, doc = decl, decl.Doc
+			break
+		}
+		node = sym.spec
+		switch spec := sym.spec.(type) {
+		case *ast.ValueSpec:
+			doc = spec.Doc
+		case *ast.TypeSpec:
+			doc = spec.Doc
+		}
+	default:
+		return false
+	}
+
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	edits := []textEdit{l.deleteLinesEdit(start, node.End())}
+	if l.flags.edits != "" {
+		l.addSuggestedFix(sym, fmt.Sprintf("delete unused %s", sym.ident.Name), edits)
+	} else {
+		l.pending = append(l.pending, edits...)
+	}
+	return true
+}
+
+func canDeleteSpec(info *types.Info, decl *ast.GenDecl, spec ast.Spec) bool {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return true
+	case *ast.ValueSpec:
+		if len(spec.Names) != 1 {
+			return false
+		}
+		// Removing a spec from a const group changes iota
+		// values and implicit repetitions of the next specs.
+		if decl.Tok == token.CONST && len(decl.Specs) != 1 {
+			return false
+		}
+		for _, v := range spec.Values {
+			if !isInert(info, v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// isInert reports whether e evaluation can't have any side effects
+// and can't panic: it's a constant or a composite literal of them.
+// Calls, receives, index expressions and derefs are never inert.
+func isInert(info *types.Info, e ast.Expr) bool {
+	if tv, ok := info.Types[e]; ok && (tv.Value != nil || tv.IsNil()) {
+		return true
+	}
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return isInert(info, e.X)
+	case *ast.UnaryExpr:
+		// &T{} is still a composite literal.
+		_, ok := ast.Unparen(e.X).(*ast.CompositeLit)
+		return e.Op == token.AND && ok && isInert(info, e.X)
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				// Struct field keys are not expressions.
+				if _, isField := kv.Key.(*ast.Ident); !isField && !isInert(info, kv.Key) {
+					return false
+				}
+				elt = kv.Value
+			}
+			if !isInert(info, elt) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// deleteLinesEdit returns an edit that removes the whole lines
+// that are occupied by the [start, end) range.
+func (l *linter) deleteLinesEdit(start, end token.Pos) textEdit {
+	f := l.fset.File(start)
+	startLine := f.Line(start)
+	endLine := f.Line(end)
+
+	endOffset := f.Size()
+	if endLine < f.LineCount() {
+		endOffset = f.Offset(f.LineStart(endLine + 1))
+	}
+	return textEdit{
+		filename: f.Name(),
+		start:    f.Offset(f.LineStart(startLine)),
+		end:      endOffset,
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"go/format"
+	"testing"
+)
+
+func TestDeleteDead(t *testing.T) {
+	dir := copyFixture(t, "dead")
+	out := runTool(t, dir, "-renamer=inprocess", "-delete-dead", "./...")
+	wantLines(t, out,
+		"trying to unexport Recv... (success)",
+		"trying to unexport Deref... (success)",
+		"trying to unexport Index... (success)",
+		"trying to unexport Call... (success)",
+		"trying to unexport Composite... (deleted)",
+		"trying to unexport Addr... (deleted)",
+		"trying to unexport Unused... (deleted)",
+		"trying to unexport Use... (success)",
+	)
+
+	src := readFile(t, dir, "lib/lib.go")
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != src {
+		t.Errorf("the edited file is not gofmt'd:\n%s", src)
+	}
+	checkBuild(t, dir)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDedupeSameNamedMethods(t *testing.T) {
+	// Same-named methods of different types are different symbols,
+	// but every symbol is reported once even for overlapping patterns.
+	dir := copyFixture(t, "dedupe")
+	out := runTool(t, dir, "-renamer=inprocess", "-v", "-dedupe-results",
+		"-json-summary", filepath.Join(dir, "summary.json"), "./p", "./...")
+	for _, want := range []string{
+		"example.com/dedupe/p method A.Name: Name -> name",
+		"example.com/dedupe/p method B.Name: Name -> name",
+	} {
+		if n := strings.Count(out, want); n != 1 {
+			t.Errorf("%q is reported %d times:\n%s", want, n, out)
+		}
+	}
+	wantLines(t, out, "(5 -> 0)")
+	if renames := readSummary(t, dir, "summary.json"); len(renames) != 5 {
+		t.Errorf("have %d renames in the summary, want 5: %+v", len(renames), renames)
+	}
+	checkBuild(t, dir)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"os"
+	"strings"
+
+	"github.com/quasilyte/go-unexport/internal/refs"
+	"golang.org/x/tools/go/packages"
+)
+
+// printDocPreview prints the packages documentation as it would look
+// after the predicted renames, similar to the go doc output.
+//
+// The renames are predicted by the in-process analysis and performed
+// over the loaded syntax trees only; no files are modified.
+func (l *linter) printDocPreview() error {
+	if !l.flags.docPreview {
+		return nil
+	}
+
+	renamed := make(map[refs.Key]string)
+	for _, sym := range l.symbols {
+		if !sym.ident.IsExported() || len(l.predictUnexport(sym)) != 0 {
+			continue
+		}
+		newName := l.unexportedName(sym)
+		renamed[l.posKey(sym.ident.Pos())] = newName
+		for _, ref := range l.refs.Refs(sym.obj) {
+			renamed[l.posKey(ref.Ident.Pos())] = newName
+		}
+	}
+
+	for i, pkg := range l.pkgs {
+		if i != 0 {
+			fmt.Println()
+		}
+		if err := l.printPackageDoc(pkg, renamed); err != nil {
+			return err
+		}
+	}
+
+	return errDone
+}
+
+func (l *linter) printPackageDoc(pkg *packages.Package, renamed map[refs.Key]string) error {
+	var files []*ast.File
+	for _, f := range pkg.Syntax {
+		filename := l.fset.Position(f.Pos()).Filename
+		if filename == "" || strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if newName, ok := renamed[l.posKey(id.Pos())]; ok {
+					id.Name = newName
+				}
+			}
+			return true
+		})
+		files = append(files, f)
+	}
+
+	p, err := doc.NewFromFiles(l.fset, files, pkg.PkgPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("package %s // import %q\n\n", p.Name, p.ImportPath)
+	if synopsis := p.Synopsis(p.Doc); synopsis != "" {
+		fmt.Printf("%s\n\n", synopsis)
+	}
+	for _, v := range p.Consts {
+		l.printDecl(v.Decl)
+	}
+	for _, v := range p.Vars {
+		l.printDecl(v.Decl)
+	}
+	for _, f := range p.Funcs {
+		l.printDecl(f.Decl)
+	}
+	for _, t := range p.Types {
+		l.printDecl(t.Decl)
+		for _, v := range t.Consts {
+			l.printDecl(v.Decl)
+		}
+		for _, v := range t.Vars {
+			l.printDecl(v.Decl)
+		}
+		for _, f := range t.Funcs {
+			l.printDecl(f.Decl)
+		}
+		for _, f := range t.Methods {
+			l.printDecl(f.Decl)
+		}
+	}
+
+	return nil
+}
+
+// posKey returns a key of the identifier at pos, which is
+// the same for all package variants that include its file.
+func (l *linter) posKey(pos token.Pos) refs.Key {
+	posn := l.fset.Position(pos)
+	return refs.Key{Filename: posn.Filename, Offset: posn.Offset}
+}
+
+func (l *linter) printDecl(decl ast.Decl) {
+	if fn, ok := decl.(*ast.FuncDecl); ok {
+		fn.Body = nil
+		fn.Doc = nil
+	}
+	if gen, ok := decl.(*ast.GenDecl); ok {
+		gen.Doc = nil
+	}
+	printer.Fprint(os.Stdout, l.fset, decl)
+	fmt.Println()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// writeDot writes a graphviz DOT graph of the -dot symbol usage:
+// the packages that reference it point to it, the edges are labeled
+// with the number of references. With "-dot all", every exported
+// candidate is included, so the whole packages API usage is shown.
+//
+// Methods are specified as Type.Method, like for -impact.
+func (l *linter) writeDot() error {
+	if l.flags.dot == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph unexport {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	found := false
+	for _, sym := range l.symbols {
+		if sym.obj == nil || !sym.ident.IsExported() {
+			continue
+		}
+		name := impactName(sym)
+		if l.flags.dot != "all" && name != l.flags.dot {
+			continue
+		}
+		found = true
+
+		node := strconv.Quote(sym.obj.Pkg().Path() + "." + name)
+		fmt.Fprintf(&buf, "\t%s [shape=ellipse];\n", node)
+		counts := make(map[string]int)
+		for _, ref := range l.refs.External(sym.obj) {
+			counts[ref.Pkg.PkgPath]++
+		}
+		paths := make([]string, 0, len(counts))
+		for path := range counts {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			fmt.Fprintf(&buf, "\t%s -> %s [label=%d];\n", strconv.Quote(path), node, counts[path])
+		}
+	}
+	buf.WriteString("}\n")
+	if !found {
+		return fmt.Errorf("%s is not found among the candidates", l.flags.dot)
+	}
+
+	if l.flags.dotOut == "" {
+		os.Stdout.Write(buf.Bytes())
+	} else if err := os.WriteFile(l.flags.dotOut, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return errDone
+}
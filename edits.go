@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonTree mirrors the go/analysis JSON output format, so the
+// emitted edits can be applied by the existing tooling.
+//
+// It maps package ID to analyzer name to diagnostics.
+type jsonTree map[string]map[string][]jsonDiagnostic
+
+type jsonDiagnostic struct {
+	Posn           string             `json:"posn"`
+	Message        string             `json:"message"`
+	SuggestedFixes []jsonSuggestedFix `json:"suggested_fixes,omitempty"`
+}
+
+type jsonSuggestedFix struct {
+	Message string         `json:"message"`
+	Edits   []jsonTextEdit `json:"edits"`
+}
+
+type jsonTextEdit struct {
+	Filename string `json:"filename"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	New      string `json:"new"`
+}
+
+// addSuggestedFix records sym edits as a suggested fix.
+func (l *linter) addSuggestedFix(sym *symbol, message string, edits []textEdit) {
+	fix := jsonSuggestedFix{Message: message}
+	for _, e := range edits {
+		fix.Edits = append(fix.Edits, jsonTextEdit{
+			Filename: l.relPath(e.filename),
+			Start:    e.start,
+			End:      e.end,
+			New:      e.newText,
+		})
+	}
+
+	diagnostics := l.edits[sym.pkg.ID]
+	if diagnostics == nil {
+		diagnostics = make(map[string][]jsonDiagnostic)
+		l.edits[sym.pkg.ID] = diagnostics
+	}
+	diagnostics["unexport"] = append(diagnostics["unexport"], jsonDiagnostic{
+		Posn:           l.position(sym.ident.Pos()).String(),
+		Message:        fmt.Sprintf("%s can be unexported", sym.ident.Name),
+		SuggestedFixes: []jsonSuggestedFix{fix},
+	})
+}
+
+func (l *linter) writeEdits() error {
+	if l.flags.edits == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(l.edits, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.flags.edits, data, 0644)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedTypes(t *testing.T) {
+	// The embedded field uses are renamed with the type:
+	// both the composite literal keys and the selectors.
+	dir := copyFixture(t, "embedded")
+	out := runTool(t, dir, "-renamer=inprocess", "./p", "./q", "./cmd/...")
+	wantLines(t, out,
+		"trying to unexport T... (success)",
+		"q/q.go:7:2 in a struct with a t field)",
+		"trying to unexport Init... (impossible: would make it a package initializer)",
+	)
+	src := readFile(t, dir, "p/p.go")
+	for _, want := range []string{"type s struct{ t }", "s{t: t{X: 1}}", "return v.t.X", "func Init() {}"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("p/p.go doesn't contain %q:\n%s", want, src)
+		}
+	}
+	if out := goRun(t, dir, "./cmd/app"); out != "2\n" {
+		t.Errorf("the program output changed: %q", out)
+	}
+}
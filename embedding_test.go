@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestCrossPackageEmbedding(t *testing.T) {
+	// The methods promoted by a type of another package
+	// are used by that package even if it doesn't name them.
+	dir := copyFixture(t, "embedding")
+	out := runTool(t, dir, "-renamer=inprocess", "./...")
+	wantLines(t, out,
+		"trying to unexport Foo... (impossible: would break interface assignability)",
+		"trying to unexport Bar... (impossible: would break package clients)",
+		"trying to unexport Qux... (success)",
+	)
+	checkBuild(t, dir)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// explainFeasibility prints all reasons that prevent every symbol
+// from being unexported, not just the first one that is hit.
+//
+// Symbols that can be unexported are assumed to be renamed,
+// so the later symbols are checked against their new names.
+func (l *linter) explainFeasibility() error {
+	if !l.flags.explainFeasibility {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, sym := range l.symbols {
+		if !sym.ident.IsExported() {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s: %s:", l.position(sym.ident.Pos()), sym.ident.Name)
+		if l.unexportedName(sym) == sym.ident.Name {
+			// Not a candidate, see processSymbol.
+			fmt.Fprintln(&buf, " no-op: already minimal")
+			continue
+		}
+		reasons := l.predictUnexport(sym)
+		if len(reasons) == 0 {
+			fmt.Fprintln(&buf, " feasible")
+			continue
+		}
+		fmt.Fprintln(&buf)
+		for _, reason := range reasons {
+			fmt.Fprintf(&buf, "\t- %s\n", reason)
+		}
+		if len(l.blockingRefs(sym)) != 0 {
+			fmt.Fprintf(&buf, "\t  used by: %s\n", strings.Join(l.blockingPackages(sym), ", "))
+		}
+	}
+
+	os.Stdout.Write(buf.Bytes())
+	if err := l.writeFeasibilityCache(buf.String()); err != nil {
+		return err
+	}
+	return l.feasibilityDone(buf.String())
+}
+
+// feasibilityDone returns the error that finishes the run after
+// the report is printed. The check verb fails if the report
+// has any feasible symbol, so it can be used as a CI gate.
+func (l *linter) feasibilityDone(report string) error {
+	if l.verb == "check" && strings.Contains(report, ": feasible\n") {
+		return errCandidates
+	}
+	return errDone
+}
+
+// predictUnexport returns all reasons that prevent sym from being
+// unexported. If there are none, sym is marked as renamed, so the
+// later predictions take it into account. See resetRenamed.
+//
+// A symbol that the name style leaves as is has nothing to prevent.
+func (l *linter) predictUnexport(sym *symbol) []string {
+	newName := l.unexportedName(sym)
+	if newName == sym.ident.Name {
+		return nil
+	}
+
+	var reasons []string
+	if other := l.cycles[sym]; other != nil {
+		reasons = append(reasons, "rename cycle with "+other.ident.Name)
+	}
+	if reason := l.semverBlock(sym); reason != "" {
+		reasons = append(reasons, reason)
+	}
+	if files := l.excludedFiles(sym); len(files) != 0 {
+		reasons = append(reasons, "used in build-constraint-excluded files: "+strings.Join(files, ", "))
+	}
+	if posn := l.linknamedAt(sym); posn != "" {
+		reasons = append(reasons, "mentioned in go:linkname at "+posn)
+	}
+	for _, err := range l.renameProblems(sym, newName) {
+		reasons = append(reasons, err.Error())
+	}
+	if len(reasons) == 0 {
+		l.markRenamed(sym, newName)
+	}
+	return reasons
+}
+
+// checkAllFeasible implements -only-if-all-feasible: if any exported
+// candidate can't be unexported, nothing is changed.
+//
+// The prediction is done by the in-process analysis, so with gorename
+// a rename can still fail for a reason that gorename alone detects.
+func (l *linter) checkAllFeasible() error {
+	if !l.flags.onlyIfAllFeasible {
+		return nil
+	}
+
+	blocked := 0
+	for _, sym := range l.symbols {
+		if !sym.ident.IsExported() {
+			continue
+		}
+		reasons := l.predictUnexport(sym)
+		if len(reasons) == 0 {
+			continue
+		}
+		blocked++
+		fmt.Printf("%s: %s:\n", l.position(sym.ident.Pos()), sym.ident.Name)
+		for _, reason := range reasons {
+			fmt.Printf("\t- %s\n", reason)
+		}
+	}
+	if blocked != 0 {
+		return fmt.Errorf("%d symbols can't be unexported, nothing is changed", blocked)
+	}
+
+	l.resetRenamed()
+	return nil
+}
+
+// blockingPackages returns sorted paths of the packages that
+// have sym references that can't be broken.
+func (l *linter) blockingPackages(sym *symbol) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, ref := range l.blockingRefs(sym) {
+		if !seen[ref.Pkg.PkgPath] {
+			seen[ref.Pkg.PkgPath] = true
+			paths = append(paths, ref.Pkg.PkgPath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
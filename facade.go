@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writeFacade regenerates the -facade file with the re-exports
+// of the -facade-keep symbols, so the public API can be a thin
+// facade over the implementation packages:
+//
+//	// Code generated by go-unexport; DO NOT EDIT.
+//
+//	package api
+//
+//	import (
+//		"example.com/lib/internal/impl"
+//	)
+//
+//	type Client = impl.Client
+//
+//	const Version = impl.Version
+//
+//	func NewClient(addr string) *impl.Client {
+//		return impl.NewClient(addr)
+//	}
+//
+// Kept symbols are never unexported. Methods come with their types.
+// Vars and generic symbols can't be re-exported without changing
+// their semantics, so they are not included; neither are the funcs
+// with unexported types in their signatures.
+//
+// A name that is kept in several packages can only be re-exported once,
+// such collisions are reported as errors.
+func (l *linter) writeFacade() error {
+	if l.flags.facade == "" || len(l.facadeSyms) == 0 {
+		return nil
+	}
+
+	pkgName, err := facadePackageName(l.flags.facade)
+	if err != nil {
+		return err
+	}
+
+	imports := make(map[string]string) // Package name => path
+	var importErr error
+	addImport := func(name, path string) {
+		if other, ok := imports[name]; ok && other != path && importErr == nil {
+			importErr = fmt.Errorf("%s and %s have the same package name", other, path)
+		}
+		imports[name] = path
+	}
+	qualifier := func(pkg *types.Package) string {
+		addImport(pkg.Name(), pkg.Path())
+		return pkg.Name()
+	}
+
+	exportedBy := make(map[string]string) // Facade name => package path
+	var typeDecls, consts, funcs []string
+	for _, sym := range l.facadeSyms {
+		name := sym.ident.Name
+		reason := facadeSkipReason(sym)
+		if reason == "" && sym.kind == "func" {
+			reason = l.facadeSignatureReason(sym.obj.(*types.Func))
+		}
+		if reason != "" {
+			if l.flags.verbose {
+				fmt.Printf("facade: skipping %s: %s\n", name, reason)
+			}
+			continue
+		}
+		if path, ok := exportedBy[name]; ok && path != sym.pkg.PkgPath {
+			return fmt.Errorf("facade: %s is exported by both %s and %s", name, path, sym.pkg.PkgPath)
+		}
+		exportedBy[name] = sym.pkg.PkgPath
+		addImport(sym.pkg.Name, sym.pkg.PkgPath)
+		ref := sym.pkg.Name + "." + name
+		switch sym.kind {
+		case "type":
+			typeDecls = append(typeDecls, fmt.Sprintf("type %s = %s", name, ref))
+		case "const":
+			consts = append(consts, fmt.Sprintf("const %s = %s", name, ref))
+		case "func":
+			funcs = append(funcs, facadeFunc(sym.obj.(*types.Func), ref, qualifier))
+		}
+	}
+	if importErr != nil {
+		return importErr
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go-unexport; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", pkgName)
+	paths := make([]string, 0, len(imports))
+	for _, path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n")
+	for _, group := range [][]string{typeDecls, consts, funcs} {
+		sort.Strings(group)
+		for _, decl := range group {
+			fmt.Fprintf(&buf, "\n%s\n", decl)
+		}
+	}
+
+	data, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.flags.facade, data, 0644)
+}
+
+// facadeSkipReason returns a reason why sym can't be re-exported by the facade.
+func facadeSkipReason(sym *symbol) string {
+	switch sym.kind {
+	case "var":
+		return "vars can't be re-exported"
+	case "method":
+		return "methods come with their types"
+	}
+	switch obj := sym.obj.(type) {
+	case *types.TypeName:
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() != 0 {
+			return "generic types can't be re-exported"
+		}
+	case *types.Func:
+		if obj.Type().(*types.Signature).TypeParams().Len() != 0 {
+			return "generic funcs can't be re-exported"
+		}
+	case nil:
+		return "no type info"
+	}
+	return ""
+}
+
+// facadeFunc returns the declaration of a facade func
+// that calls fn, referenced as ref, with the same arguments.
+func facadeFunc(fn *types.Func, ref string, qualifier types.Qualifier) string {
+	sig := fn.Type().(*types.Signature)
+	pkgName := strings.Split(ref, ".")[0]
+
+	// Parameters are renamed if they're blank or would shadow the
+	// package name in the call. The names are only seen in the docs.
+	params := make([]string, sig.Params().Len())
+	args := make([]string, sig.Params().Len())
+	used := make(map[string]bool)
+	for i := range params {
+		used[sig.Params().At(i).Name()] = true
+	}
+	for i := range params {
+		param := sig.Params().At(i)
+		name := param.Name()
+		if name == "" || name == "_" || name == pkgName {
+			for n := i; ; n++ {
+				name = fmt.Sprintf("p%d", n)
+				if !used[name] {
+					break
+				}
+			}
+			used[name] = true
+		}
+		typ := types.TypeString(param.Type(), qualifier)
+		args[i] = name
+		if sig.Variadic() && i == len(params)-1 {
+			typ = "..." + types.TypeString(param.Type().(*types.Slice).Elem(), qualifier)
+			args[i] += "..."
+		}
+		params[i] = name + " " + typ
+	}
+
+	results := make([]string, sig.Results().Len())
+	for i := range results {
+		results[i] = types.TypeString(sig.Results().At(i).Type(), qualifier)
+	}
+	call := fmt.Sprintf("%s(%s)", ref, strings.Join(args, ", "))
+	switch len(results) {
+	case 0:
+		return fmt.Sprintf("func %s(%s) {\n\t%s\n}", fn.Name(), strings.Join(params, ", "), call)
+	case 1:
+		return fmt.Sprintf("func %s(%s) %s {\n\treturn %s\n}", fn.Name(), strings.Join(params, ", "), results[0], call)
+	default:
+		return fmt.Sprintf("func %s(%s) (%s) {\n\treturn %s\n}", fn.Name(), strings.Join(params, ", "), strings.Join(results, ", "), call)
+	}
+}
+
+// facadeSignatureReason returns a reason why a facade func can't
+// repeat the fn signature: other packages can't name unexported types,
+// including the ones that were unexported by this run.
+func (l *linter) facadeSignatureReason(fn *types.Func) string {
+	if name := l.hiddenTypeName(fn.Type()); name != "" {
+		return "its signature uses unexported " + name
+	}
+	return ""
+}
+
+// hiddenTypeName returns the first name mentioned by typ
+// that can't be used outside of its package, or an empty string.
+func (l *linter) hiddenTypeName(typ types.Type) string {
+	switch typ := typ.(type) {
+	case interface {
+		Obj() *types.TypeName
+		TypeArgs() *types.TypeList
+	}: // *types.Named and *types.Alias
+		obj := typ.Obj()
+		if obj.Pkg() != nil && (!obj.Exported() || l.renamed[l.refs.KeyOf(obj)]) {
+			return obj.Pkg().Name() + "." + obj.Name()
+		}
+		for i := 0; i < typ.TypeArgs().Len(); i++ {
+			if name := l.hiddenTypeName(typ.TypeArgs().At(i)); name != "" {
+				return name
+			}
+		}
+	case *types.Pointer:
+		return l.hiddenTypeName(typ.Elem())
+	case *types.Slice:
+		return l.hiddenTypeName(typ.Elem())
+	case *types.Array:
+		return l.hiddenTypeName(typ.Elem())
+	case *types.Chan:
+		return l.hiddenTypeName(typ.Elem())
+	case *types.Map:
+		if name := l.hiddenTypeName(typ.Key()); name != "" {
+			return name
+		}
+		return l.hiddenTypeName(typ.Elem())
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{typ.Params(), typ.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if name := l.hiddenTypeName(tuple.At(i).Type()); name != "" {
+					return name
+				}
+			}
+		}
+	case *types.Struct:
+		for i := 0; i < typ.NumFields(); i++ {
+			field := typ.Field(i)
+			if !field.Exported() {
+				return "field " + field.Name()
+			}
+			if name := l.hiddenTypeName(field.Type()); name != "" {
+				return name
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < typ.NumExplicitMethods(); i++ {
+			method := typ.ExplicitMethod(i)
+			if !method.Exported() {
+				return "method " + method.Name()
+			}
+			if name := l.hiddenTypeName(method.Type()); name != "" {
+				return name
+			}
+		}
+		for i := 0; i < typ.NumEmbeddeds(); i++ {
+			if name := l.hiddenTypeName(typ.EmbeddedType(i)); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// facadePackageName returns the package name for the facade file.
+// It's taken from the other Go files of the same directory;
+// if there are none, the directory name is used.
+func facadePackageName(filename string) (string, error) {
+	dir := filepath.Dir(filename)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	abs, _ := filepath.Abs(filename)
+	fset := token.NewFileSet()
+	for _, f := range files {
+		if other, _ := filepath.Abs(f); other == abs || strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, f, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return parsed.Name.Name, nil
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := strings.NewReplacer("-", "_", ".", "_").Replace(filepath.Base(absDir))
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("can't derive a package name from %s", absDir)
+	}
+	return name, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFacade(t *testing.T) {
+	dir := copyFixture(t, "facade")
+	out := runTool(t, dir, "-renamer=inprocess", "-v", "-facade=api/facade.go",
+		"-facade-keep=Version,Client,Option,NewClient,Close,NewDebug,Inspect,Default", "./internal/impl")
+	wantLines(t, out,
+		"trying to unexport Helper... (success)",
+		"facade: skipping NewDebug: its signature uses unexported impl.Helper",
+		"facade: skipping Inspect: its signature uses unexported impl.state",
+		"facade: skipping Default: vars can't be re-exported",
+	)
+
+	src := readFile(t, dir, "api/facade.go")
+	for _, want := range []string{
+		"package api",
+		"type Client = impl.Client",
+		"const Version = impl.Version",
+		"func NewClient(ctx context.Context, p1 string, opts ...impl.Option) (*impl.Client, error) {\n\treturn impl.NewClient(ctx, p1, opts...)\n}",
+		"func Close(p0 *impl.Client, p1 int) {\n\timpl.Close(p0, p1)\n}",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("facade doesn't contain %q:\n%s", want, src)
+		}
+	}
+	for _, unwant := range []string{"NewDebug", "Inspect", "Default"} {
+		if strings.Contains(src, unwant) {
+			t.Errorf("facade contains %q:\n%s", unwant, src)
+		}
+	}
+	checkBuild(t, dir)
+}
+
+func TestFacadeCollision(t *testing.T) {
+	dir := copyFixture(t, "facade")
+	out, err := runToolErr(t, dir, "-renamer=inprocess", "-facade=api/facade.go", "-facade-keep=Version", "./internal/...")
+	if err == nil {
+		t.Fatalf("colliding names are re-exported:\n%s", out)
+	}
+	wantLines(t, out, "facade: Version is exported by both example.com/facade/internal/impl and example.com/facade/internal/other")
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// feasibilityCache is the -feasibility-cache file contents.
+type feasibilityCache struct {
+	Key    string `json:"key"`
+	Report string `json:"report"`
+}
+
+// readFeasibilityCache prints the cached -explain-feasibility report
+// if the inputs are unchanged since it was written, so loading the
+// targets and the references analysis can be skipped.
+// See computeFeasibilityKey for the inputs.
+func (l *linter) readFeasibilityCache() error {
+	if l.flags.feasibilityCache == "" {
+		return nil
+	}
+
+	key, err := l.computeFeasibilityKey()
+	if err != nil {
+		return err
+	}
+	l.feasibilityKey = key
+
+	data, err := os.ReadFile(l.flags.feasibilityCache)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var cache feasibilityCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Key != key {
+		return nil // Invalid or outdated, will be overwritten
+	}
+	fmt.Print(cache.Report)
+	return l.feasibilityDone(cache.Report)
+}
+
+func (l *linter) writeFeasibilityCache(report string) error {
+	if l.flags.feasibilityCache == "" {
+		return nil
+	}
+	data, err := json.Marshal(feasibilityCache{Key: l.feasibilityKey, Report: report})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.flags.feasibilityCache, data, 0644)
+}
+
+// computeFeasibilityKey hashes the -feasibility-cache inputs:
+// the command line arguments, the ignore file, the -coverage profile,
+// the -manifest and the sources of the targets and their dependencies.
+//
+// The sources are found with the packages.Load mode that doesn't
+// parse or type check anything, so a cache hit stays cheap.
+// The standard library is covered by the Go version.
+func (l *linter) computeFeasibilityKey() (string, error) {
+	files, err := l.feasibilityFiles()
+	if err != nil {
+		return "", err
+	}
+	if root, err := findModuleRoot(); err == nil && root != "" {
+		files = append(files, filepath.Join(root, ignoreFilename))
+	}
+	files = append(files, l.flags.coverage, l.flags.manifest)
+	sort.Strings(files)
+
+	h := sha256.New()
+	io.WriteString(h, "feasibility-v2 "+runtime.Version()+"\n"+strings.Join(os.Args[1:], "\n")+"\n")
+	seen := make(map[string]bool, len(files))
+	for _, filename := range files {
+		if filename == "" || seen[filename] {
+			continue
+		}
+		seen[filename] = true
+		data, err := os.ReadFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(h, "%s missing\n", filename)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(h, "%s %x\n", filename, sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// feasibilityFiles returns the source files the report depends on,
+// build-constraint-excluded files included.
+func (l *linter) feasibilityFiles() ([]string, error) {
+	if l.flags.manifest != "" {
+		data, err := os.ReadFile(l.flags.manifest)
+		if err != nil {
+			return nil, err
+		}
+		var m manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		dir := filepath.Dir(l.flags.manifest)
+		var files []string
+		for _, pkg := range m.Packages {
+			for _, filename := range pkg.Files {
+				files = append(files, filepath.Join(dir, filename))
+			}
+		}
+		for _, filename := range m.Export {
+			files = append(files, filepath.Join(dir, filename))
+		}
+		return files, nil
+	}
+
+	if l.flags.subtree != "" {
+		if err := l.resolveSubtree(); err != nil {
+			return nil, err
+		}
+	}
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Tests: true,
+	}
+	pkgs, err := packages.Load(cfg, l.flags.targets...)
+	if err != nil {
+		return nil, err
+	}
+	goroot := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	var files []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if len(pkg.GoFiles) != 0 && strings.HasPrefix(pkg.GoFiles[0], goroot) {
+			return
+		}
+		files = append(files, pkg.GoFiles...)
+		files = append(files, pkg.OtherFiles...)
+		files = append(files, pkg.IgnoredFiles...)
+	})
+	return files, nil
+}
@@ -0,0 +1,13 @@
+module github.com/quasilyte/go-unexport
+
+go 1.26.0
+
+require (
+	github.com/go-toolsmith/pkgload v1.0.0
+	golang.org/x/tools v0.50.0
+)
+
+require (
+	golang.org/x/mod v0.41.0 // indirect
+	golang.org/x/sync v0.23.0 // indirect
+)
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// targetGroup is a set of packages with its own -unexport and -skip lists.
+//
+// Groups are described by the repeatable -group flag, as a list
+// of semicolon-separated key=value pairs:
+//
+//	-group 'name=api;pkgs=./api/...;unexport=Foo,Bar'
+//	-group 'name=internal;pkgs=./internal/a,./internal/b;skip=Must*'
+//
+// pkgs is required, all other keys are optional. An empty unexport list
+// means "all symbols". Global -skip patterns apply to every group.
+// A package that matches several groups belongs to the first of them.
+type targetGroup struct {
+	name     string
+	patterns []string
+	unexport map[string]bool
+	skip     []string
+
+	// paths is a set of the matched package paths.
+	paths map[string]bool
+}
+
+// groupsFlag is a repeatable -group flag value.
+type groupsFlag []*targetGroup
+
+func (f *groupsFlag) String() string {
+	names := make([]string, len(*f))
+	for i, g := range *f {
+		names[i] = g.name
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *groupsFlag) Set(s string) error {
+	g := &targetGroup{name: fmt.Sprintf("group%d", len(*f)+1)}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", part)
+		}
+		switch key {
+		case "name":
+			g.name = value
+		case "pkgs":
+			g.patterns = splitList(value)
+		case "unexport":
+			g.unexport = make(map[string]bool)
+			for _, name := range splitList(value) {
+				g.unexport[name] = true
+			}
+		case "skip":
+			for _, pattern := range splitList(value) {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("skip: %q: %v", pattern, err)
+				}
+				g.skip = append(g.skip, pattern)
+			}
+		default:
+			return fmt.Errorf("unknown key %q", key)
+		}
+	}
+	if len(g.patterns) == 0 {
+		return fmt.Errorf("group %s: pkgs are not specified", g.name)
+	}
+	*f = append(*f, g)
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			list = append(list, x)
+		}
+	}
+	return list
+}
+
+// resolveGroups finds the package paths matched by every group.
+func (l *linter) resolveGroups() error {
+	for _, g := range l.flags.groups {
+		pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName}, g.patterns...)
+		if err != nil {
+			return fmt.Errorf("group %s: %v", g.name, err)
+		}
+		g.paths = make(map[string]bool, len(pkgs))
+		for _, pkg := range pkgs {
+			g.paths[pkg.PkgPath] = true
+		}
+	}
+	return nil
+}
+
+// groupAllows reports whether sym should be collected according to
+// its package group settings. It also records sym group.
+// Without -group, all symbols are allowed.
+func (l *linter) groupAllows(sym *symbol) bool {
+	if len(l.flags.groups) == 0 {
+		return true
+	}
+	pkgPath := strings.TrimSuffix(sym.pkg.PkgPath, "_test")
+	for _, g := range l.flags.groups {
+		if !g.paths[pkgPath] {
+			continue
+		}
+		sym.group = g
+		if g.unexport != nil && !g.unexport[sym.ident.Name] {
+			return false
+		}
+		for _, pattern := range g.skip {
+			if ok, _ := path.Match(pattern, sym.ident.Name); ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
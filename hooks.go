@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// renamedFiles returns sorted names of the files that are
+// touched by sym rename: its declaration and references files.
+func (l *linter) renamedFiles(sym *symbol) []string {
+	seen := map[string]bool{
+		l.fset.Position(sym.ident.Pos()).Filename: true,
+	}
+	for _, ref := range l.refs.Refs(sym.obj) {
+		seen[l.fset.Position(ref.Ident.Pos()).Filename] = true
+	}
+	files := make([]string, 0, len(seen))
+	for filename := range seen {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// afterRename runs the -after-each hook for the files touched by sym rename.
+//
+// The in-process renamer modifies the files only after all symbols are
+// processed, so its hooks are deferred until then. See runHooks.
+func (l *linter) afterRename(files []string) {
+	if l.flags.afterEach == "" || l.flags.edits != "" {
+		return
+	}
+	if l.flags.renamer == "inprocess" {
+		l.hookFiles = append(l.hookFiles, files...)
+		return
+	}
+	for _, filename := range files {
+		if err := runHook(l.flags.afterEach, filename); err != nil {
+			log.Printf("after-each hook for %s: %v", filename, err)
+		}
+	}
+}
+
+// runHooks runs deferred -after-each hooks and the -after-all hook.
+// Nothing is run with -edits as no files are modified.
+func (l *linter) runHooks() error {
+	if l.flags.edits != "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, filename := range l.hookFiles {
+		if seen[filename] {
+			continue
+		}
+		seen[filename] = true
+		if err := runHook(l.flags.afterEach, filename); err != nil {
+			log.Printf("after-each hook for %s: %v", filename, err)
+		}
+	}
+
+	if l.flags.afterAll == "" || len(l.success) == 0 && len(l.shimmed) == 0 {
+		return nil
+	}
+	return runHook(l.flags.afterAll, "")
+}
+
+// runHook runs cmd with every {file} replaced by filename.
+// The command is split by spaces, no shell is involved.
+// It's split before the replacement, so filename
+// is never split even if it contains spaces.
+func runHook(cmd, filename string) error {
+	args := strings.Fields(cmd)
+	if len(args) == 0 {
+		return errors.New("empty command")
+	}
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(arg, "{file}", filename)
+	}
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
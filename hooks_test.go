@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAfterEachHook(t *testing.T) {
+	// The hook gets the file name as a single argument,
+	// even if it contains spaces.
+	src := copyFixture(t, "dead")
+	dir := filepath.Join(filepath.Dir(src), "with space")
+	if err := os.Rename(src, dir); err != nil {
+		t.Fatal(err)
+	}
+	runTool(t, dir, "-renamer=inprocess", "-delete-dead", "-unexport", "Unused", "-after-each", "touch {file}.hooked", "./...")
+	if _, err := os.Stat(filepath.Join(dir, "lib", "lib.go.hooked")); err != nil {
+		t.Errorf("the hook was not run for the deleted symbol file: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFilename is a name of the file with skip patterns
+// that is looked up at the module root.
+//
+// Every line is a glob pattern, like the ones -skip accepts.
+// Patterns that end with ".go" or contain "/" are matched
+// against the file paths that are relative to the module root,
+// patterns without "/" are matched against the file base name.
+// Other patterns are matched against the symbol names.
+// Empty lines and lines that start with "#" are ignored.
+//
+//	# Keep the API of generated files.
+//	*_gen.go
+//	pkg/api/*.go
+//	Must*
+const ignoreFilename = ".go-unexport-ignore"
+
+func (l *linter) readIgnoreFile() error {
+	root, err := findModuleRoot()
+	if err != nil || root == "" {
+		return err
+	}
+	l.moduleRoot = root
+
+	filename := filepath.Join(root, ignoreFilename)
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s:%d: %q: %v", filename, line, pattern, err)
+		}
+		if strings.HasSuffix(pattern, ".go") || strings.Contains(pattern, "/") {
+			l.skipFiles = append(l.skipFiles, pattern)
+		} else {
+			l.skip = append(l.skip, pattern)
+		}
+	}
+	return scanner.Err()
+}
+
+// isSkipped reports whether sym matches any of the skip patterns.
+func (l *linter) isSkipped(sym *symbol) bool {
+	for _, pattern := range l.skip {
+		if ok, _ := path.Match(pattern, sym.ident.Name); ok {
+			return true
+		}
+	}
+	if len(l.skipFiles) == 0 {
+		return false
+	}
+
+	filename := l.fset.Position(sym.ident.Pos()).Filename
+	rel, err := filepath.Rel(l.moduleRoot, filename)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range l.skipFiles {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findModuleRoot returns the closest directory with go.mod
+// file, starting from the working directory.
+// Returns an empty string if there is no such directory.
+func findModuleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// printImpact prints the packages that would be affected by unexporting
+// the -impact symbol: the packages that directly reference it. All of
+// them depend on the declaring package; the number of all (transitive)
+// dependents is printed too, to show the potential blast radius.
+//
+// Methods are specified as Type.Method.
+func (l *linter) printImpact() error {
+	if l.flags.impact == "" {
+		return nil
+	}
+
+	found := false
+	for _, sym := range l.symbols {
+		if sym.obj == nil || impactName(sym) != l.flags.impact {
+			continue
+		}
+		found = true
+
+		counts := make(map[string]int)
+		for _, ref := range l.refs.External(sym.obj) {
+			counts[ref.Pkg.PkgPath]++
+		}
+		paths := make([]string, 0, len(counts))
+		for path := range counts {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		dependents := l.dependents(sym.obj.Pkg().Path())
+		fmt.Printf("%s: %s: referenced by %d of %d dependent packages\n",
+			l.position(sym.ident.Pos()), l.flags.impact, len(paths), len(dependents))
+		for _, path := range paths {
+			fmt.Printf("\t%s: %d refs\n", path, counts[path])
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s is not found among the candidates", l.flags.impact)
+	}
+
+	return errDone
+}
+
+// impactName returns sym name in the -impact format.
+func impactName(sym *symbol) string {
+	if fn, ok := sym.obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			typ := recv.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			if named, ok := typ.(*types.Named); ok {
+				return named.Obj().Name() + "." + sym.ident.Name
+			}
+		}
+	}
+	return sym.ident.Name
+}
+
+// dependents returns a set of loaded package paths that
+// depend on pkgPath, directly or transitively.
+// The package own test variants are not included,
+// but its external test package is.
+func (l *linter) dependents(pkgPath string) map[string]bool {
+	memo := make(map[*packages.Package]bool)
+	var dependsOn func(pkg *packages.Package) bool
+	dependsOn = func(pkg *packages.Package) bool {
+		if result, ok := memo[pkg]; ok {
+			return result
+		}
+		memo[pkg] = false // Break the cycles through test variants
+		for _, imp := range pkg.Imports {
+			if imp.PkgPath == pkgPath || dependsOn(imp) {
+				memo[pkg] = true
+				return true
+			}
+		}
+		return false
+	}
+
+	set := make(map[string]bool)
+	for _, pkg := range l.loaded {
+		path := pkg.PkgPath
+		if path != pkgPath && !strings.HasSuffix(path, ".test") && dependsOn(pkg) {
+			set[path] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,172 @@
+package refs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cacheVersion should be changed every time the cached data format
+// or its meaning changes, so the old entries are never reused.
+const cacheVersion = "refs-v3"
+
+// NewCachedIndex is like NewIndex, but the references of the packages
+// are reused from the cacheDir if the package sources (and the sources
+// of all packages it depends on, directly or transitively) were not
+// changed since they were stored there. References of the other packages are added to the cache.
+//
+// Cached references have their own idents that only carry the name and
+// the position of the reference; they're not the ones from pkg.Syntax.
+func NewCachedIndex(fset *token.FileSet, pkgs []*packages.Package, cacheDir string) (*Index, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, err
+	}
+
+	c := &refsCache{
+		dir:  cacheDir,
+		keys: make(map[*packages.Package]string, len(pkgs)),
+	}
+
+	idx := newIndex(fset)
+	roots := rootPaths(pkgs)
+	for _, pkg := range pkgs {
+		key, err := c.key(pkg)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := c.load(idx, pkg, key)
+		if !ok {
+			list = idx.packageRefs(pkg, roots)
+			if err := c.store(idx, key, list); err != nil {
+				return nil, err
+			}
+		}
+		idx.add(pkg, list)
+	}
+	idx.finish(pkgs)
+
+	return idx, nil
+}
+
+type refsCache struct {
+	dir  string
+	keys map[*packages.Package]string
+}
+
+type cachedRef struct {
+	DeclFile   string `json:"decl_file"`
+	DeclOffset int    `json:"decl_offset"`
+	File       string `json:"file"`
+	Offset     int    `json:"offset"`
+	Name       string `json:"name"`
+}
+
+// key returns a content hash of pkg sources combined
+// with the keys of the packages it imports.
+//
+// All dependencies are included, not only the loaded ones: a package
+// can reach a loaded package through another one, so the cached decl
+// offsets depend on it. Standard library packages are covered by the
+// Go version instead, it's cheaper than hashing their sources.
+func (c *refsCache) key(pkg *packages.Package) (string, error) {
+	if key, ok := c.keys[pkg]; ok {
+		return key, nil
+	}
+
+	h := sha256.New()
+	io.WriteString(h, cacheVersion+" "+runtime.Version()+"\n"+pkg.ID+"\n")
+	for _, filename := range pkg.CompiledGoFiles {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		io.WriteString(h, filename+" "+hex.EncodeToString(sum[:])+"\n")
+	}
+
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		imp := pkg.Imports[path]
+		if isStd(imp) {
+			continue
+		}
+		key, err := c.key(imp)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, path+" "+key+"\n")
+	}
+
+	key := hex.EncodeToString(h.Sum(nil))
+	c.keys[pkg] = key
+	return key, nil
+}
+
+// isStd reports whether pkg is a standard library package.
+func isStd(pkg *packages.Package) bool {
+	goroot := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	return len(pkg.GoFiles) != 0 && strings.HasPrefix(pkg.GoFiles[0], goroot)
+}
+
+func (c *refsCache) load(idx *Index, pkg *packages.Package, key string) ([]declRef, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var cached []cachedRef
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+
+	files := make(map[string]*token.File, len(pkg.Syntax))
+	for _, f := range pkg.Syntax {
+		tf := idx.fset.File(f.Pos())
+		files[tf.Name()] = tf
+	}
+	list := make([]declRef, 0, len(cached))
+	for _, r := range cached {
+		tf := files[r.File]
+		if tf == nil || r.Offset > tf.Size() {
+			return nil, false
+		}
+		list = append(list, declRef{
+			decl:  Key{Filename: r.DeclFile, Offset: r.DeclOffset},
+			ident: &ast.Ident{Name: r.Name, NamePos: tf.Pos(r.Offset)},
+		})
+	}
+	return list, true
+}
+
+func (c *refsCache) store(idx *Index, key string, list []declRef) error {
+	cached := make([]cachedRef, 0, len(list))
+	for _, r := range list {
+		posn := idx.fset.Position(r.ident.Pos())
+		cached = append(cached, cachedRef{
+			DeclFile:   r.decl.Filename,
+			DeclOffset: r.decl.Offset,
+			File:       posn.Filename,
+			Offset:     posn.Offset,
+			Name:       r.ident.Name,
+		})
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, key+".json"), data, 0644)
+}
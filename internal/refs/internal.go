@@ -0,0 +1,32 @@
+package refs
+
+import (
+	"strings"
+)
+
+// InternalTree reports whether pkgPath is an internal package that
+// can be imported by the importerPath package only due to the internal
+// visibility rules.
+//
+// Such packages have no importers outside of their tree,
+// so all of their clients are usually in the same repository.
+func InternalTree(pkgPath, importerPath string) bool {
+	root, ok := internalRoot(pkgPath)
+	if !ok {
+		return false
+	}
+	importerPath = strings.TrimSuffix(importerPath, "_test")
+	return root == "" || importerPath == root || strings.HasPrefix(importerPath, root+"/")
+}
+
+// internalRoot returns a root of the tree pkgPath is visible in.
+// The last "internal" path element is the most restrictive one.
+func internalRoot(pkgPath string) (string, bool) {
+	parts := strings.Split(pkgPath, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "internal" {
+			return strings.Join(parts[:i], "/"), true
+		}
+	}
+	return "", false
+}
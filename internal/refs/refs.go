@@ -0,0 +1,579 @@
+// Package refs implements the reference analysis that is shared
+// by the go-unexport command and its analyzer form.
+package refs
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Key identifies a declaration by its source position.
+//
+// Objects can't be compared directly as the same declaration
+// gets a distinct object in every package variant (like the
+// test variant of a package), but its position stays the same.
+type Key struct {
+	Filename string
+	Offset   int
+}
+
+// Ref is a single symbol reference.
+type Ref struct {
+	Pkg   *packages.Package
+	Ident *ast.Ident
+}
+
+// Index maps package-level symbols and methods of the loaded packages
+// to their references.
+type Index struct {
+	// FormatPos is used to print positions in the returned errors.
+	// If nil, token.Position String method is used.
+	FormatPos func(token.Position) string
+
+	fset *token.FileSet
+
+	refs   map[Key][]Ref
+	ifaces []*types.Interface
+
+	// named are the non-generic package-level named types
+	// of the loaded packages. They're used to find the
+	// types that get methods promoted through embedding.
+	named []*types.Named
+
+	// selections maps the selector identifiers positions
+	// to their selections, see selectionConflict.
+	selections map[token.Pos]*types.Selection
+	// selectedBy maps the selected names to the sorted
+	// selector identifiers positions.
+	selectedBy map[string][]token.Pos
+
+	// embedded maps the embedded fields keys to their structs.
+	// The field is keyed at its type name identifier, which
+	// is a reference to the embedded type, see Refs.
+	embedded map[Key]*types.Struct
+
+	// reexports contains keys of the references that
+	// re-export a symbol under another package API.
+	reexports map[Key]bool
+
+	// seen contains already added references keys.
+	// Only used while the index is being built.
+	seen map[Key]bool
+}
+
+// NewIndex builds an index over the given packages.
+// All packages are expected to share the same fset.
+func NewIndex(fset *token.FileSet, pkgs []*packages.Package) *Index {
+	idx := newIndex(fset)
+	roots := rootPaths(pkgs)
+	for _, pkg := range pkgs {
+		idx.add(pkg, idx.packageRefs(pkg, roots))
+	}
+	idx.finish(pkgs)
+	return idx
+}
+
+// declRef is a reference to the declaration identified by decl.
+type declRef struct {
+	decl  Key
+	ident *ast.Ident
+}
+
+func newIndex(fset *token.FileSet) *Index {
+	return &Index{
+		fset:      fset,
+		refs:      make(map[Key][]Ref),
+		seen:      make(map[Key]bool),
+		reexports: make(map[Key]bool),
+	}
+}
+
+// rootPaths returns a set of pkgs import paths.
+func rootPaths(pkgs []*packages.Package) map[string]bool {
+	roots := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		roots[pkg.PkgPath] = true
+	}
+	return roots
+}
+
+// packageRefs returns pkg references to the symbols of the roots packages.
+func (idx *Index) packageRefs(pkg *packages.Package, roots map[string]bool) []declRef {
+	if pkg.TypesInfo == nil {
+		return nil
+	}
+
+	var list []declRef
+	for id, obj := range pkg.TypesInfo.Uses {
+		if !(isSymbol(obj) || isEmbeddedField(obj)) || !roots[obj.Pkg().Path()] {
+			continue
+		}
+		list = append(list, declRef{decl: idx.KeyOf(obj), ident: id})
+	}
+	return list
+}
+
+func (idx *Index) add(pkg *packages.Package, list []declRef) {
+	for _, r := range list {
+		// Package variants share the files, so the same
+		// reference can be reported more than once.
+		refKey := idx.keyOf(r.ident.Pos())
+		if idx.seen[refKey] {
+			continue
+		}
+		idx.seen[refKey] = true
+		idx.refs[r.decl] = append(idx.refs[r.decl], Ref{Pkg: pkg, Ident: r.ident})
+	}
+}
+
+func (idx *Index) finish(pkgs []*packages.Package) {
+	for _, list := range idx.refs {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Ident.Pos() < list[j].Ident.Pos()
+		})
+	}
+	idx.seen = nil
+	idx.ifaces = collectInterfaces(pkgs)
+	idx.named = collectNamed(pkgs)
+	idx.selections, idx.selectedBy = collectSelections(pkgs)
+	idx.embedded = collectEmbedded(idx.fset, pkgs)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			idx.collectReexports(f)
+		}
+	}
+}
+
+// collectReexports marks the references that make another package
+// symbol a part of f package API:
+//
+//	type Foo = a.Foo
+//	var Foo = a.Foo
+//	const Foo = a.Foo
+func (idx *Index) collectReexports(f *ast.File) {
+	mark := func(name *ast.Ident, e ast.Expr) {
+		if !name.IsExported() {
+			return
+		}
+		if sel, ok := ast.Unparen(e).(*ast.SelectorExpr); ok {
+			idx.reexports[idx.keyOf(sel.Sel.Pos())] = true
+		}
+	}
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				if spec.Assign.IsValid() {
+					mark(spec.Name, spec.Type)
+				}
+			case *ast.ValueSpec:
+				if len(spec.Names) != len(spec.Values) {
+					continue
+				}
+				for i, name := range spec.Names {
+					mark(name, spec.Values[i])
+				}
+			}
+		}
+	}
+}
+
+// IsReexport reports whether ref re-exports a symbol as
+// a part of the ref package API, like in type Foo = a.Foo.
+func (idx *Index) IsReexport(ref Ref) bool {
+	return idx.reexports[idx.keyOf(ref.Ident.Pos())]
+}
+
+// KeyOf returns the obj declaration key.
+func (idx *Index) KeyOf(obj types.Object) Key {
+	return idx.keyOf(obj.Pos())
+}
+
+// Refs returns all obj references, declaration excluded.
+//
+// A type that is embedded in a struct is also referenced by the
+// uses of the embedded field, like s.T or S{T: t}: they have to
+// be renamed together with the type.
+func (idx *Index) Refs(obj types.Object) []Ref {
+	refs := idx.refs[idx.KeyOf(obj)]
+	if _, ok := obj.(*types.TypeName); !ok {
+		return refs
+	}
+	var fieldRefs []Ref
+	for _, ref := range refs {
+		key := idx.keyOf(ref.Ident.Pos())
+		if idx.embedded[key] != nil {
+			fieldRefs = append(fieldRefs, idx.refs[key]...)
+		}
+	}
+	if len(fieldRefs) == 0 {
+		return refs
+	}
+	return append(append([]Ref{}, refs...), fieldRefs...)
+}
+
+// External returns obj references that come from other packages.
+// External test packages are considered to be other packages too.
+func (idx *Index) External(obj types.Object) []Ref {
+	var external []Ref
+	for _, ref := range idx.Refs(obj) {
+		if ref.Pkg.PkgPath != obj.Pkg().Path() {
+			external = append(external, ref)
+		}
+	}
+	return external
+}
+
+// RequiredByInterface reports whether obj is a method that can
+// be used to satisfy some interface, so renaming it could change
+// the program behavior or break the build.
+func (idx *Index) RequiredByInterface(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	typ := recv.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	for _, iface := range idx.ifaces {
+		if !hasMethod(iface, fn.Name()) {
+			continue
+		}
+		// Implements behavior is unspecified for generic types,
+		// a method name match is good enough to be conservative.
+		if named.TypeParams().Len() != 0 {
+			return true
+		}
+		if implements(named, iface) {
+			return true
+		}
+		// A type that embeds named (possibly from another package)
+		// can satisfy the interface thanks to the promoted method.
+		for _, other := range idx.promotes(fn) {
+			if implements(other, iface) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// promotes returns the named types that get method fn promoted
+// through embedding, the receiver type itself excluded.
+func (idx *Index) promotes(fn *types.Func) []*types.Named {
+	key := idx.KeyOf(fn)
+	var list []*types.Named
+	for _, named := range idx.named {
+		obj, index, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, fn.Pkg(), fn.Name())
+		if obj == nil || len(index) < 2 || idx.KeyOf(obj) != key {
+			continue
+		}
+		list = append(list, named)
+	}
+	return list
+}
+
+func implements(named *types.Named, iface *types.Interface) bool {
+	return types.Implements(named, iface) || types.Implements(types.NewPointer(named), iface)
+}
+
+// Conflict returns an error if renaming obj to newName would
+// introduce a conflict or change the meaning of some reference.
+//
+// Objects for which ignore returns true are not considered to be
+// conflicting; it can be used to skip the objects that are being
+// renamed too. A nil ignore func never skips anything.
+func (idx *Index) Conflict(obj types.Object, newName string, ignore func(types.Object) bool) error {
+	if !token.IsIdentifier(newName) {
+		return fmt.Errorf("%q is not a valid identifier", newName)
+	}
+	conflicts := func(other types.Object) bool {
+		return other != nil && (ignore == nil || !ignore(other))
+	}
+
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			if other, _, _ := types.LookupFieldOrMethod(recv.Type(), true, obj.Pkg(), newName); conflicts(other) {
+				return fmt.Errorf("conflicts with %s", other)
+			}
+			return idx.selectionConflict(obj, newName, conflicts)
+		}
+	}
+
+	if _, ok := obj.(*types.Func); ok && newName == "init" {
+		return errors.New("would make it a package initializer")
+	}
+	if types.Universe.Lookup(newName) != nil {
+		return fmt.Errorf("would shadow predeclared %s", newName)
+	}
+	scope := obj.Pkg().Scope()
+	if other := scope.Lookup(newName); conflicts(other) {
+		return fmt.Errorf("conflicts with %s", other)
+	}
+	for i := 0; i < scope.NumChildren(); i++ {
+		if other := scope.Child(i).Lookup(newName); conflicts(other) {
+			return fmt.Errorf("conflicts with %s", other)
+		}
+	}
+	for _, ref := range idx.refs[idx.KeyOf(obj)] {
+		if ref.Pkg.PkgPath != obj.Pkg().Path() {
+			continue
+		}
+		// The embedded field gets the new name too.
+		if st := idx.embedded[idx.keyOf(ref.Ident.Pos())]; st != nil {
+			for i := 0; i < st.NumFields(); i++ {
+				if field := st.Field(i); field.Name() == newName && conflicts(field) {
+					posn := idx.formatPos(idx.fset.Position(ref.Ident.Pos()))
+					return fmt.Errorf("embedded at %s in a struct with a %s field", posn, newName)
+				}
+			}
+		}
+		inner := ref.Pkg.Types.Scope().Innermost(ref.Ident.Pos())
+		if inner == nil {
+			continue
+		}
+		if _, other := inner.LookupParent(newName, ref.Ident.Pos()); conflicts(other) {
+			posn := idx.formatPos(idx.fset.Position(ref.Ident.Pos()))
+			return fmt.Errorf("reference at %s would be shadowed by %s", posn, other)
+		}
+	}
+
+	return nil
+}
+
+// selectionConflict checks that every selection stays the same after
+// obj method is renamed to newName. Types that embed the receiver
+// can have their own newName field or method:
+//
+//	type U struct {
+//		T
+//		foo int
+//	}
+//
+// Then U{}.Foo() can't become U{}.foo(), it would select the field.
+// And the other way around, the renamed method can take over
+// the existing selections of a deeper embedded newName.
+func (idx *Index) selectionConflict(obj types.Object, newName string, conflicts func(types.Object) bool) error {
+	declKey := idx.KeyOf(obj)
+	for _, ref := range idx.Refs(obj) {
+		sel := idx.selections[ref.Ident.Pos()]
+		if sel == nil {
+			continue
+		}
+		other, index, _ := types.LookupFieldOrMethod(sel.Recv(), true, sel.Obj().Pkg(), newName)
+		if other == nil && index == nil || len(index) > len(sel.Index()) {
+			continue
+		}
+		if other != nil && (idx.KeyOf(other) == declKey || !conflicts(other)) {
+			continue
+		}
+		posn := idx.formatPos(idx.fset.Position(ref.Ident.Pos()))
+		if other == nil {
+			return fmt.Errorf("reference at %s would be ambiguous", posn)
+		}
+		return fmt.Errorf("reference at %s would select %s", posn, other)
+	}
+
+	for _, pos := range idx.selectedBy[newName] {
+		sel := idx.selections[pos]
+		selected := sel.Obj()
+		if selected.Pkg() == nil || selected.Pkg().Path() != obj.Pkg().Path() {
+			continue
+		}
+		found, index, _ := types.LookupFieldOrMethod(sel.Recv(), true, selected.Pkg(), obj.Name())
+		if found == nil || idx.KeyOf(found) != declKey || len(index) > len(sel.Index()) {
+			continue
+		}
+		posn := idx.formatPos(idx.fset.Position(pos))
+		return fmt.Errorf("selection of %s at %s would select the renamed %s", selected, posn, obj.Name())
+	}
+
+	return nil
+}
+
+// collectSelections returns the selections of pkgs keyed by their
+// selector identifier position, and these positions grouped by
+// the selected name. The cached references have their own
+// identifiers, so the positions are used instead.
+func collectSelections(pkgs []*packages.Package) (map[token.Pos]*types.Selection, map[string][]token.Pos) {
+	selections := make(map[token.Pos]*types.Selection)
+	selectedBy := make(map[string][]token.Pos)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for expr, sel := range pkg.TypesInfo.Selections {
+			pos := expr.Sel.Pos()
+			if selections[pos] == nil {
+				selectedBy[expr.Sel.Name] = append(selectedBy[expr.Sel.Name], pos)
+			}
+			selections[pos] = sel
+		}
+	}
+	for _, list := range selectedBy {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+	return selections, selectedBy
+}
+
+func (idx *Index) formatPos(posn token.Position) string {
+	if idx.FormatPos != nil {
+		return idx.FormatPos(posn)
+	}
+	return posn.String()
+}
+
+func (idx *Index) keyOf(pos token.Pos) Key {
+	posn := idx.fset.Position(pos)
+	return Key{Filename: posn.Filename, Offset: posn.Offset}
+}
+
+// isEmbeddedField reports whether obj is an embedded struct field.
+func isEmbeddedField(obj types.Object) bool {
+	v, ok := obj.(*types.Var)
+	return ok && v.Embedded() && v.Pkg() != nil
+}
+
+// collectEmbedded returns the structs of pkgs keyed
+// by the positions of their embedded fields.
+func collectEmbedded(fset *token.FileSet, pkgs []*packages.Package) map[Key]*types.Struct {
+	embedded := make(map[Key]*types.Struct)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for expr, tv := range pkg.TypesInfo.Types {
+			if _, ok := expr.(*ast.StructType); !ok {
+				continue
+			}
+			st, ok := tv.Type.(*types.Struct)
+			if !ok {
+				continue
+			}
+			for i := 0; i < st.NumFields(); i++ {
+				if field := st.Field(i); field.Embedded() {
+					posn := fset.Position(field.Pos())
+					embedded[Key{Filename: posn.Filename, Offset: posn.Offset}] = st
+				}
+			}
+		}
+	}
+	return embedded
+}
+
+// isSymbol reports whether obj is a package-level symbol or a method.
+func isSymbol(obj types.Object) bool {
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		if fn.Type().(*types.Signature).Recv() != nil {
+			return true
+		}
+	}
+	return obj.Parent() == obj.Pkg().Scope()
+}
+
+// collectInterfaces returns all interfaces that are mentioned in the
+// loaded packages or declared in any of the packages they depend on.
+func collectInterfaces(pkgs []*packages.Package) []*types.Interface {
+	var ifaces []*types.Interface
+	seen := make(map[*types.Interface]bool)
+	add := func(typ types.Type) {
+		iface, ok := typ.Underlying().(*types.Interface)
+		if !ok || seen[iface] || iface.NumMethods() == 0 || !iface.IsMethodSet() {
+			return
+		}
+		seen[iface] = true
+		ifaces = append(ifaces, iface)
+	}
+
+	add(types.Universe.Lookup("error").Type())
+
+	visited := make(map[*types.Package]bool)
+	var visitPkg func(pkg *types.Package)
+	visitPkg = func(pkg *types.Package) {
+		if visited[pkg] {
+			return
+		}
+		visited[pkg] = true
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+				if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() != 0 {
+					continue
+				}
+				add(tn.Type())
+			}
+		}
+		for _, imp := range pkg.Imports() {
+			visitPkg(imp)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Types != nil {
+			visitPkg(pkg.Types)
+		}
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, tv := range pkg.TypesInfo.Types {
+			if tv.IsType() {
+				add(tv.Type)
+			}
+		}
+	}
+
+	return ifaces
+}
+
+// collectNamed returns the non-generic package-level named types of pkgs.
+func collectNamed(pkgs []*packages.Package) []*types.Named {
+	var list []*types.Named
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() == 0 {
+				list = append(list, named)
+			}
+		}
+	}
+	return list
+}
+
+func hasMethod(iface *types.Interface, name string) bool {
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == name {
+			return true
+		}
+	}
+	return false
+}
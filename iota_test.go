@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPartialIotaUnexport(t *testing.T) {
+	dir := copyFixture(t, "iota")
+	before := goRun(t, dir, "./cmd/values")
+
+	out := runTool(t, dir, "-renamer=inprocess", "./...")
+	wantLines(t, out,
+		"trying to unexport A... (success)",
+		"trying to unexport B... (impossible: would break package clients)",
+		"trying to unexport Y... (success)",
+	)
+	src := readFile(t, dir, "consts/consts.go")
+	if !strings.Contains(src, "a = 1 << iota\n\tB\n\tC\n") {
+		t.Errorf("unexpected consts:\n%s", src)
+	}
+
+	checkBuild(t, dir)
+	if after := goRun(t, dir, "./cmd/values"); after != before {
+		t.Errorf("values changed: %q -> %q", before, after)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// isImported reports whether pkg is imported by any other loaded package.
+// Packages that are not imported are leaf packages: their exported symbols
+// can't have cross-package users within the loaded set.
+//
+// The pkg own test packages are not counted as importers.
+func (l *linter) isImported(pkg *packages.Package) bool {
+	if l.imported == nil {
+		l.imported = make(map[string]bool)
+		for _, importer := range l.loaded {
+			self := strings.TrimSuffix(strings.TrimSuffix(importer.PkgPath, "_test"), ".test")
+			for path := range importer.Imports {
+				if path != self {
+					l.imported[path] = true
+				}
+			}
+		}
+	}
+	return l.imported[pkg.PkgPath]
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"go/types"
+	"strings"
+)
+
+// linknamedAt returns a position of the go:linkname directive that
+// mentions sym, or an empty string if there are none.
+//
+// Such references are invisible to the type checker: renaming sym
+// would break the linkage silently, so these symbols are kept as is.
+func (l *linter) linknamedAt(sym *symbol) string {
+	if !l.flags.keepLinknamed {
+		return ""
+	}
+	if l.linknames == nil {
+		l.linknames = l.scanLinknames()
+	}
+	for _, name := range linknameNames(sym) {
+		if posn, ok := l.linknames[name]; ok {
+			return posn
+		}
+	}
+	return ""
+}
+
+// scanLinknames maps the symbols mentioned in the go:linkname
+// directives of the loaded packages to the directive positions.
+//
+// Both local and remote names are recorded as "pkgpath.name".
+func (l *linter) scanLinknames() map[string]string {
+	linknames := make(map[string]string)
+	for _, pkg := range l.loaded {
+		for _, f := range pkg.Syntax {
+			for _, group := range f.Comments {
+				for _, c := range group.List {
+					if !strings.HasPrefix(c.Text, "//go:linkname ") {
+						continue
+					}
+					posn := l.position(c.Pos()).String()
+					fields := strings.Fields(c.Text)[1:]
+					if len(fields) >= 1 {
+						linknames[pkg.PkgPath+"."+fields[0]] = posn
+					}
+					if len(fields) >= 2 {
+						linknames[fields[1]] = posn
+					}
+				}
+			}
+		}
+	}
+	return linknames
+}
+
+// linknameNames returns the names sym can be mentioned by in go:linkname.
+func linknameNames(sym *symbol) []string {
+	prefix := sym.pkg.PkgPath + "."
+	name := sym.ident.Name
+	fn, ok := sym.obj.(*types.Func)
+	if !ok {
+		return []string{prefix + name}
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return []string{prefix + name}
+	}
+	typ := recv.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return nil
+	}
+	typeName := named.Obj().Name()
+	return []string{
+		prefix + typeName + "." + name,
+		prefix + "(*" + typeName + ")." + name,
+	}
+}
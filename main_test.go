@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// toolPath is the go-unexport binary built by TestMain.
+var toolPath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "go-unexport-test")
+	if err != nil {
+		panic(err)
+	}
+	toolPath = filepath.Join(dir, "go-unexport")
+	if out, err := exec.Command("go", "build", "-o", toolPath, ".").CombinedOutput(); err != nil {
+		panic(string(out))
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// copyFixture copies testdata/name module to a temporary directory,
+// so the tool can modify it.
+func copyFixture(t *testing.T, name string) string {
+	t.Helper()
+	dst := t.TempDir()
+	src := filepath.Join("testdata", name)
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+// goCommand returns a command that runs in dir with the fixture friendly environment.
+func goCommand(dir, name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=off", "GOPROXY=off")
+	return cmd
+}
+
+// runTool runs go-unexport in dir and returns its output.
+// The test fails if the tool exits with an error.
+func runTool(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := runToolErr(t, dir, args...)
+	if err != nil {
+		t.Fatalf("go-unexport %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// runToolErr is like runTool, but it returns the tool error.
+func runToolErr(t testing.TB, dir string, args ...string) (string, error) {
+	t.Helper()
+	out, err := goCommand(dir, toolPath, args...).CombinedOutput()
+	return string(out), err
+}
+
+// checkBuild fails the test if dir module packages,
+// tests included, don't compile.
+func checkBuild(t *testing.T, dir string) {
+	t.Helper()
+	if out, err := goCommand(dir, "go", "vet", "./...").CombinedOutput(); err != nil {
+		t.Fatalf("build failed: %v\n%s", err, out)
+	}
+}
+
+// goRun runs the main package at path of dir module and returns its output.
+func goRun(t *testing.T, dir, path string) string {
+	t.Helper()
+	out, err := goCommand(dir, "go", "run", path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run %s: %v\n%s", path, err, out)
+	}
+	return string(out)
+}
+
+// readFile returns the dir relative file contents.
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// wantLines fails the test if any of the lines is not in out.
+func wantLines(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(out, line) {
+			t.Errorf("output doesn't contain %q:\n%s", line, out)
+		}
+	}
+}
+
+// unwantLines fails the test if any of the lines is in out.
+func unwantLines(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if strings.Contains(out, line) {
+			t.Errorf("output contains %q:\n%s", line, out)
+		}
+	}
+}
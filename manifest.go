@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
+)
+
+// manifest describes the packages to process for the build systems
+// where go list is not the source of truth. For example:
+//
+//	{
+//		"packages": [
+//			{"path": "example.com/foo", "files": ["foo/foo.go", "foo/util.go"]},
+//			{"path": "example.com/foo_test", "files": ["foo/foo_test.go"]}
+//		],
+//		"export": {
+//			"example.com/dep": "out/dep.a"
+//		}
+//	}
+//
+// Relative file paths are resolved against the manifest file directory.
+//
+// Imports of the manifest packages are type-checked from the sources.
+// Other imports are read from the export data files; imports that are
+// not listed there are resolved by the default importer.
+type manifest struct {
+	Packages []manifestPackage `json:"packages"`
+	Export   map[string]string `json:"export"`
+}
+
+type manifestPackage struct {
+	Path  string   `json:"path"`
+	Files []string `json:"files"`
+}
+
+func loadManifest(fset *token.FileSet, filename string) ([]*packages.Package, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", filename, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	ld := &manifestLoader{
+		fset:     fset,
+		dir:      dir,
+		manifest: &m,
+		byPath:   make(map[string]*manifestPackage),
+		loaded:   make(map[string]*packages.Package),
+		exported: make(map[string]*types.Package),
+		fallback: importer.Default(),
+	}
+	for i := range m.Packages {
+		ld.byPath[m.Packages[i].Path] = &m.Packages[i]
+	}
+
+	pkgs := make([]*packages.Package, 0, len(m.Packages))
+	for i := range m.Packages {
+		pkg, err := ld.load(&m.Packages[i])
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+type manifestLoader struct {
+	fset     *token.FileSet
+	dir      string
+	manifest *manifest
+	byPath   map[string]*manifestPackage
+	loaded   map[string]*packages.Package
+	exported map[string]*types.Package
+	fallback types.Importer
+}
+
+func (ld *manifestLoader) Import(path string) (*types.Package, error) {
+	if mp := ld.byPath[path]; mp != nil {
+		pkg, err := ld.load(mp)
+		if err != nil {
+			return nil, err
+		}
+		return pkg.Types, nil
+	}
+
+	if pkg := ld.exported[path]; pkg != nil && pkg.Complete() {
+		return pkg, nil
+	}
+	if filename, ok := ld.manifest.Export[path]; ok {
+		f, err := os.Open(ld.abs(filename))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r, err := gcexportdata.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("read %s export data: %v", path, err)
+		}
+		return gcexportdata.Read(r, ld.fset, ld.exported, path)
+	}
+
+	return ld.fallback.Import(path)
+}
+
+func (ld *manifestLoader) load(mp *manifestPackage) (*packages.Package, error) {
+	if pkg, ok := ld.loaded[mp.Path]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle via %s", mp.Path)
+		}
+		return pkg, nil
+	}
+	ld.loaded[mp.Path] = nil // Mark as in progress
+
+	pkg := &packages.Package{
+		ID:      mp.Path,
+		PkgPath: mp.Path,
+		Fset:    ld.fset,
+		Imports: make(map[string]*packages.Package),
+	}
+	for _, filename := range mp.Files {
+		filename = ld.abs(filename)
+		f, err := parser.ParseFile(ld.fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg.GoFiles = append(pkg.GoFiles, filename)
+		pkg.Syntax = append(pkg.Syntax, f)
+	}
+	pkg.CompiledGoFiles = pkg.GoFiles
+	if len(pkg.Syntax) != 0 {
+		pkg.Name = pkg.Syntax[0].Name.Name
+	}
+
+	pkg.TypesInfo = &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	conf := types.Config{
+		Importer: ld,
+		Error: func(err error) {
+			pkg.Errors = append(pkg.Errors, packages.Error{
+				Msg:  err.Error(),
+				Kind: packages.TypeError,
+			})
+		},
+	}
+	// Errors are collected by the callback above.
+	pkg.Types, _ = conf.Check(mp.Path, ld.fset, pkg.Syntax, pkg.TypesInfo)
+	for _, imp := range pkg.Types.Imports() {
+		if dep := ld.loaded[imp.Path()]; dep != nil {
+			pkg.Imports[imp.Path()] = dep
+		}
+	}
+
+	ld.loaded[mp.Path] = pkg
+	return pkg, nil
+}
+
+func (ld *manifestLoader) abs(filename string) string {
+	if filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(ld.dir, filename)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMethodReferences(t *testing.T) {
+	// Method values and method expressions are updated in a single
+	// rename. An interface method can't be renamed separately
+	// from its implementations, so its calls are kept as is.
+	dir := copyFixture(t, "methodrefs")
+	out := runTool(t, dir, "-renamer=inprocess", "./...")
+	wantLines(t, out,
+		"trying to unexport Value... (success)",
+		"trying to unexport Expr... (success)",
+		"trying to unexport Call... (impossible: would break interface assignability)",
+	)
+	src := readFile(t, dir, "p/p.go")
+	for _, want := range []string{"f := t.value", "g := T.expr", "c.Call()", "func (impl) Call() int"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("p/p.go doesn't contain %q:\n%s", want, src)
+		}
+	}
+	if out := goRun(t, dir, "./cmd/app"); out != "6\n" {
+		t.Errorf("the program output changed: %q", out)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// nameStyles maps -name-style values to the name transforms.
+var nameStyles = map[string]func(string) string{
+	"lower-first": toLowerFirst,
+	"acronym":     toLowerAcronym,
+}
+
+// symbolKinds lists all symbol kinds.
+var symbolKinds = []string{"func", "method", "type", "var", "const"}
+
+// unexportedName returns an unexported form of sym name,
+// according to its kind name style.
+func (l *linter) unexportedName(sym *symbol) string {
+	if f := l.kindStyles[sym.kind]; f != nil {
+		return f(sym.ident.Name)
+	}
+	return l.toUnexported(sym.ident.Name)
+}
+
+func toLowerFirst(s string) string {
+	if s == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+func toUpperFirst(s string) string {
+	if s == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// toLowerAcronym lowers the leading upper case letters run,
+// so the leading acronyms are lowered as a whole.
+// The last letter of the run is kept if it starts the next word.
+//
+//	URLParser => urlParser
+//	ID        => id
+//	Foo       => foo
+func toLowerAcronym(s string) string {
+	runes := []rune(s)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestNoopRenames(t *testing.T) {
+	for _, style := range []string{"-name-style=lower-first", "-name-style=acronym", "-name-style-func=acronym"} {
+		t.Run(style, func(t *testing.T) {
+			dir := copyFixture(t, "noop")
+			out := runTool(t, dir, "-renamer=inprocess", style, "./...")
+			wantLines(t, out,
+				"trying to unexport ϒFoo... (no-op: already minimal)",
+				"trying to unexport Other... (success)",
+				"(2 -> 1)",
+			)
+			checkBuild(t, dir)
+		})
+	}
+
+	// A no-op is not a failure either.
+	dir := copyFixture(t, "noop")
+	out := runTool(t, dir, "-renamer=inprocess", "-unexport", "ϒFoo", "-require-requested", "./...")
+	wantLines(t, out, "trying to unexport ϒFoo... (no-op: already minimal)")
+}
+
+func TestNoopFeasibility(t *testing.T) {
+	dir := copyFixture(t, "noop")
+	out, err := runToolErr(t, dir, "check", "-unexport", "ϒFoo", "./...")
+	if err != nil {
+		t.Fatalf("check failed: %v\n%s", err, out)
+	}
+	wantLines(t, out, "ϒFoo: no-op: already minimal")
+	unwantLines(t, out, "conflict", "feasible")
+
+	out = runTool(t, dir, "-renamer=inprocess", "-only-if-all-feasible", "./...")
+	wantLines(t, out,
+		"trying to unexport ϒFoo... (no-op: already minimal)",
+		"trying to unexport Other... (success)",
+	)
+	checkBuild(t, dir)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// addNotify records the packages that prevent sym from being
+// unexported for the -notify-list.
+func (l *linter) addNotify(sym *symbol) {
+	if l.flags.notifyList == "" || sym.obj == nil {
+		return
+	}
+	for _, path := range l.blockingPackages(sym) {
+		if l.notify == nil {
+			l.notify = make(map[string][]string)
+		}
+		l.notify[path] = append(l.notify[path], sym.obj.Pkg().Path()+"."+impactName(sym))
+	}
+}
+
+// writeNotifyList writes a markdown task list of the packages
+// that need to stop using the symbols before they can be unexported.
+// Every package is followed by the symbols it references.
+func (l *linter) writeNotifyList() error {
+	if l.flags.notifyList == "" {
+		return nil
+	}
+
+	paths := make([]string, 0, len(l.notify))
+	for path := range l.notify {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "- [ ] %s\n", path)
+		syms := l.notify[path]
+		sort.Strings(syms)
+		for _, sym := range syms {
+			fmt.Fprintf(&buf, "  - %s\n", sym)
+		}
+	}
+	return os.WriteFile(l.flags.notifyList, []byte(buf.String()), 0644)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"github.com/quasilyte/go-unexport/internal/refs"
+)
+
+// orderSymbols reorders symbols, so the rename that frees a name is
+// attempted before the rename that takes it. Otherwise the second
+// rename would be rejected due to a transient name collision.
+//
+// Renames that form a cycle (a -> b, b -> a) can't be ordered;
+// such symbols are recorded in l.cycles and never renamed.
+func (l *linter) orderSymbols() error {
+	byName := make(map[string]*symbol)
+	for _, sym := range l.symbols {
+		if sym.obj != nil {
+			byName[l.scopeOf(sym)+"."+sym.ident.Name] = sym
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*symbol]int, len(l.symbols))
+	ordered := make([]*symbol, 0, len(l.symbols))
+	var stack []*symbol
+	var visit func(sym *symbol)
+	visit = func(sym *symbol) {
+		switch state[sym] {
+		case visited:
+			return
+		case visiting:
+			// Every symbol on the stack after sym is a part of the cycle.
+			for i := len(stack) - 1; i >= 0; i-- {
+				next := sym
+				if i != len(stack)-1 {
+					next = stack[i+1]
+				}
+				l.cycles[stack[i]] = next
+				if stack[i] == sym {
+					break
+				}
+			}
+			return
+		}
+
+		state[sym] = visiting
+		stack = append(stack, sym)
+		if newName := l.targetName(sym); newName != "" {
+			if dep := byName[l.scopeOf(sym)+"."+newName]; dep != nil && dep != sym {
+				visit(dep)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[sym] = visited
+		ordered = append(ordered, sym)
+	}
+	for _, sym := range l.symbols {
+		visit(sym)
+	}
+
+	l.symbols = ordered
+	return nil
+}
+
+// targetName returns a name sym would be renamed to.
+// Returns an empty string if sym is not going to be renamed.
+func (l *linter) targetName(sym *symbol) string {
+	switch {
+	case sym.obj == nil:
+		return ""
+	case ast.IsExported(sym.ident.Name):
+		return l.unexportedName(sym)
+	case l.flags.checkNaming && l.flags.fix:
+		return toLowerAcronym(toUpperFirst(sym.ident.Name))
+	default:
+		return ""
+	}
+}
+
+// scopeOf returns a key of the scope sym is declared in.
+// Methods are scoped by their receiver type.
+func (l *linter) scopeOf(sym *symbol) string {
+	if fn, ok := sym.obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			typ := recv.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			if named, ok := typ.(*types.Named); ok {
+				key := l.refs.KeyOf(named.Obj())
+				return fmt.Sprintf("%s:%d", key.Filename, key.Offset)
+			}
+		}
+	}
+	return sym.pkg.PkgPath
+}
+
+// markRenamed records a successful sym rename (or deletion, if newName is empty).
+func (l *linter) markRenamed(sym *symbol, newName string) {
+	if sym.obj == nil {
+		return
+	}
+	l.renamed[l.refs.KeyOf(sym.obj)] = true
+	if newName != "" {
+		l.taken[l.scopeOf(sym)+"."+newName] = sym
+	}
+}
+
+// resetRenamed forgets all recorded renames.
+// It's used after the renames are only predicted.
+func (l *linter) resetRenamed() {
+	l.renamed = make(map[refs.Key]bool)
+	l.taken = make(map[string]*symbol)
+}
+
+// isRenamed reports whether obj was renamed (or deleted) during this run.
+func (l *linter) isRenamed(obj types.Object) bool {
+	return l.renamed[l.refs.KeyOf(obj)]
+}
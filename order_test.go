@@ -0,0 +1,102 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestOrderSymbols(t *testing.T) {
+	tests := []struct {
+		name    string
+		renames []string // "pkg.Old->New", in the symbols order
+		order   []string
+		cycles  []string // "Old->Next"
+	}{
+		{
+			name:    "independent",
+			renames: []string{"p.A->x", "p.B->y"},
+			order:   []string{"A", "B"},
+		},
+		{
+			name:    "chain",
+			renames: []string{"p.A->B", "p.B->C", "p.C->z"},
+			order:   []string{"C", "B", "A"},
+		},
+		{
+			name:    "same name",
+			renames: []string{"p.A->A"},
+			order:   []string{"A"},
+		},
+		{
+			name:    "other package",
+			renames: []string{"p.A->B", "q.B->A"},
+			order:   []string{"A", "B"},
+		},
+		{
+			name:    "cycle",
+			renames: []string{"p.A->B", "p.B->A"},
+			order:   []string{"B", "A"},
+			cycles:  []string{"A->B", "B->A"},
+		},
+		{
+			name:    "cycle with a tail",
+			renames: []string{"p.D->A", "p.A->B", "p.B->C", "p.C->A", "p.E->x"},
+			order:   []string{"C", "B", "A", "D", "E"},
+			cycles:  []string{"A->B", "B->C", "C->A"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var l linter
+			l.init()
+			newNames := make(map[string]string)
+			l.kindStyles = map[string]func(string) string{
+				"func": func(name string) string { return newNames[name] },
+			}
+			pkgs := make(map[string]*packages.Package)
+			for _, r := range test.renames {
+				pkgPath, rename, _ := strings.Cut(r, ".")
+				oldName, newName, _ := strings.Cut(rename, "->")
+				newNames[oldName] = newName
+				if pkgs[pkgPath] == nil {
+					pkgs[pkgPath] = &packages.Package{PkgPath: pkgPath}
+				}
+				sig := types.NewSignatureType(nil, nil, nil, nil, nil, false)
+				l.symbols = append(l.symbols, &symbol{
+					ident: ast.NewIdent(oldName),
+					obj:   types.NewFunc(token.NoPos, nil, oldName, sig),
+					pkg:   pkgs[pkgPath],
+					kind:  "func",
+				})
+			}
+
+			if err := l.orderSymbols(); err != nil {
+				t.Fatal(err)
+			}
+			var order []string
+			for _, sym := range l.symbols {
+				order = append(order, sym.ident.Name)
+			}
+			if strings.Join(order, " ") != strings.Join(test.order, " ") {
+				t.Errorf("order:\nhave: %v\nwant: %v", order, test.order)
+			}
+			var cycles []string
+			for _, sym := range l.symbols {
+				if next := l.cycles[sym]; next != nil {
+					cycles = append(cycles, sym.ident.Name+"->"+next.ident.Name)
+				}
+			}
+			sort.Strings(cycles)
+			if strings.Join(cycles, " ") != strings.Join(test.cycles, " ") {
+				t.Errorf("cycles:\nhave: %v\nwant: %v", cycles, test.cycles)
+			}
+		})
+	}
+}
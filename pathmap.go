@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// pathMapFlag is a repeatable -path-map flag value.
+type pathMapFlag []pathMapping
+
+type pathMapping struct {
+	from string
+	to   string
+}
+
+func (f *pathMapFlag) String() string {
+	parts := make([]string, len(*f))
+	for i, m := range *f {
+		parts[i] = m.from + "=" + m.to
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *pathMapFlag) Set(s string) error {
+	eq := strings.Index(s, "=")
+	if eq <= 0 {
+		return errors.New("expected from=to")
+	}
+	*f = append(*f, pathMapping{from: s[:eq], to: s[eq+1:]})
+	return nil
+}
+
+// apply replaces the s prefix using the first matching mapping.
+// A mapping matches the whole path or its leading directories.
+//
+// Paths are matched regardless of their separators, so a Windows
+// path can be mapped with a forward slash pattern. The rest of the path
+// is converted to the target path separators if they are unambiguous:
+// mapping /src to D:\src turns /src/a/b.go into D:\src\a\b.go.
+func (f pathMapFlag) apply(s string) string {
+	slashed := toSlash(s)
+	for _, m := range f {
+		from := toSlash(m.from)
+		if !strings.HasPrefix(slashed, from) {
+			continue
+		}
+		rest := s[len(from):]
+		// Only whole path elements match: /src is not a prefix of /srcfoo.
+		if rest != "" && !strings.HasSuffix(from, "/") && !strings.HasPrefix(toSlash(rest), "/") {
+			continue
+		}
+		switch {
+		case strings.Contains(m.to, `\`) && !strings.Contains(m.to, "/"):
+			rest = strings.ReplaceAll(rest, "/", `\`)
+		case strings.Contains(m.to, "/") && !strings.Contains(m.to, `\`):
+			rest = toSlash(rest)
+		}
+		return m.to + rest
+	}
+	return s
+}
+
+// toSlash is like filepath.ToSlash, but it converts the
+// Windows separators on every platform.
+func toSlash(s string) string {
+	return strings.ReplaceAll(s, `\`, "/")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestPathMapApply(t *testing.T) {
+	tests := []struct {
+		mapping string
+		path    string
+		want    string
+	}{
+		{`/home/me/proj=/src`, `/home/me/proj/a/b.go`, `/src/a/b.go`},
+		{`/home/me/proj=/src`, `/home/me/other/b.go`, `/home/me/other/b.go`},
+		{`/src=D:\src`, `/src/a/b.go`, `D:\src\a\b.go`},
+		{`D:/Desktop/proj=/src`, `D:\Desktop\proj\a\b.go`, `/src/a/b.go`},
+		{`D:\Desktop\proj=/src`, `D:\Desktop\proj\a\b.go`, `/src/a/b.go`},
+		{`D:\Desktop\proj=E:\proj`, `D:\Desktop\proj\a\b.go`, `E:\proj\a\b.go`},
+		{`/src=/dst`, `/srcfoo/a.go`, `/srcfoo/a.go`},
+		{`/src=/dst`, `/src`, `/dst`},
+		{`/src/=/dst/`, `/src/a.go`, `/dst/a.go`},
+		{`D:\src=/dst`, `D:\srcfoo\a.go`, `D:\srcfoo\a.go`},
+	}
+
+	for _, test := range tests {
+		var f pathMapFlag
+		if err := f.Set(test.mapping); err != nil {
+			t.Fatal(err)
+		}
+		if have := f.apply(test.path); have != test.want {
+			t.Errorf("%s: apply(%s):\nhave: %s\nwant: %s", test.mapping, test.path, have, test.want)
+		}
+	}
+}
+
+func TestGorenameOffset(t *testing.T) {
+	tests := []struct {
+		filename string
+		mapping  string
+		want     string
+		err      string
+	}{
+		{filename: `/home/me/a.go`, want: `/home/me/a.go:#10`},
+		{filename: `D:\Desktop\proj\a.go`, want: `D:\Desktop\proj\a.go:#10`},
+		{filename: `/src/a/b.go`, mapping: `/src=D:\Desktop\proj`, want: `D:\Desktop\proj\a\b.go:#10`},
+		{filename: `D:\Desktop\a:#b\c.go`, err: `contains ":#"`},
+	}
+
+	for _, test := range tests {
+		var l linter
+		l.fset = token.NewFileSet()
+		if test.mapping != "" {
+			var f pathMapFlag
+			if err := f.Set(test.mapping); err != nil {
+				t.Fatal(err)
+			}
+			l.offsetMapper = f.apply
+		}
+		file := l.fset.AddFile(test.filename, -1, 100)
+		have, err := l.gorenameOffset(file.Pos(10))
+		switch {
+		case test.err != "":
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: have %q, %v; want %q error", test.filename, have, err, test.err)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", test.filename, err)
+		case have != test.want:
+			t.Errorf("%s:\nhave: %s\nwant: %s", test.filename, have, test.want)
+		}
+	}
+}
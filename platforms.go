@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadPlatforms loads the targets once again for every -platforms
+// GOOS/GOARCH pair, so the references that are only visible on other
+// platforms are indexed too. Only l.loaded is extended: the symbols
+// are collected from the current platform packages.
+func (l *linter) loadPlatforms() error {
+	l.platformOf = make(map[*packages.Package]string)
+	for _, platform := range strings.Split(l.flags.platforms, ",") {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		cfg := &packages.Config{
+			Mode:  packages.LoadSyntax | packages.NeedModule,
+			Tests: true,
+			Fset:  l.fset,
+			Env:   append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch),
+		}
+		pkgs, err := packages.Load(cfg, l.flags.targets...)
+		if err != nil {
+			return fmt.Errorf("%s: %v", platform, err)
+		}
+		for _, pkg := range pkgs {
+			l.platformOf[pkg] = platform
+		}
+		l.loaded = append(l.loaded, pkgs...)
+	}
+	return nil
+}
+
+// parsePlatforms validates the -platforms GOOS/GOARCH list.
+func parsePlatforms(s string) error {
+	for _, platform := range strings.Split(s, ",") {
+		goos, goarch, ok := strings.Cut(platform, "/")
+		if !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("invalid platform %q, want GOOS/GOARCH", platform)
+		}
+	}
+	return nil
+}
+
+// blockingPlatforms returns sorted -platforms entries that have
+// sym references that can't be broken. References that are visible
+// on the current platform are not attributed to any of them.
+func (l *linter) blockingPlatforms(sym *symbol) []string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, ref := range l.blockingRefs(sym) {
+		platform, ok := l.platformOf[ref.Pkg]
+		if ok && !seen[platform] {
+			seen[platform] = true
+			list = append(list, platform)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizeReceivers renames the method receivers to the lower-case
+// abbreviation of their type name, see receiverName.
+//
+// It's a separate mode: nothing is unexported when it's enabled.
+// A receiver is left as is if the new name is already used
+// anywhere inside of its method, so no identifier is shadowed.
+func (l *linter) normalizeReceivers() error {
+	if !l.flags.normalizeReceivers {
+		return nil
+	}
+
+	seen := make(map[token.Pos]bool) // Test variants share the files
+	for _, pkg := range l.pkgs {
+		for _, f := range pkg.Syntax {
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || seen[fn.Pos()] {
+					continue
+				}
+				seen[fn.Pos()] = true
+				recv := fn.Recv.List[0]
+				if len(recv.Names) == 0 || recv.Names[0].Name == "_" {
+					continue
+				}
+				ident := recv.Names[0]
+				typeName := recvTypeName(recv.Type)
+				newName := receiverName(typeName)
+				if newName == "" || newName == ident.Name {
+					continue
+				}
+				posn := l.position(ident.Pos())
+				if token.IsKeyword(newName) || identUsed(fn, newName) {
+					if l.flags.verbose {
+						fmt.Printf("%s: %s.%s: can't rename receiver %s to %s\n", posn, typeName, fn.Name.Name, ident.Name, newName)
+					}
+					continue
+				}
+
+				obj := pkg.TypesInfo.Defs[ident]
+				edits := []textEdit{l.identEdit(ident.Pos(), ident.Name, newName)}
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					if id, ok := n.(*ast.Ident); ok && obj != nil && pkg.TypesInfo.Uses[id] == obj {
+						edits = append(edits, l.identEdit(id.Pos(), id.Name, newName))
+					}
+					return true
+				})
+				l.pending = append(l.pending, edits...)
+				fmt.Printf("%s: %s.%s: renamed receiver %s to %s\n", posn, typeName, fn.Name.Name, ident.Name, newName)
+			}
+		}
+	}
+
+	if err := l.applyEdits(); err != nil {
+		return err
+	}
+	return errDone
+}
+
+// recvTypeName returns the receiver type name without
+// the pointer and type parameters.
+func recvTypeName(typ ast.Expr) string {
+	for {
+		switch e := typ.(type) {
+		case *ast.StarExpr:
+			typ = e.X
+		case *ast.ParenExpr:
+			typ = e.X
+		case *ast.IndexExpr:
+			typ = e.X
+		case *ast.IndexListExpr:
+			typ = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// receiverName returns the lower-case initials of the
+// typeName words: "Node" becomes "n", "HTTPServer" becomes "hs".
+func receiverName(typeName string) string {
+	runes := []rune(typeName)
+	var name strings.Builder
+	for i, r := range runes {
+		if r == '_' {
+			continue
+		}
+		wordStart := i == 0 || runes[i-1] == '_'
+		if !wordStart && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			wordStart = unicode.IsLower(prev) || unicode.IsDigit(prev) || nextLower
+		}
+		if wordStart {
+			name.WriteRune(unicode.ToLower(r))
+		}
+	}
+	s := name.String()
+	if r, _ := utf8.DecodeRuneInString(s); !unicode.IsLetter(r) {
+		return ""
+	}
+	return s
+}
+
+// identUsed reports whether there is an identifier named name in fn.
+func identUsed(fn *ast.FuncDecl, name string) bool {
+	found := false
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestReexports(t *testing.T) {
+	dir := copyFixture(t, "reexport")
+	out := runTool(t, dir, "-renamer=inprocess", "-within", "./a", "./...")
+	wantLines(t, out,
+		"trying to unexport Foo... (impossible: re-exported by example.com/reexport/b)",
+		"trying to unexport Bar... (impossible: re-exported by example.com/reexport/b)",
+		"trying to unexport Baz... (impossible: re-exported by example.com/reexport/b)",
+		"trying to unexport Plain... (impossible: would break package clients)",
+	)
+
+	// Re-exports are never waived, unlike the other references.
+	out = runTool(t, dir, "-renamer=inprocess", "-within", "./a", "-allow-breaking-prefix", "example.com/reexport/b", "./...")
+	wantLines(t, out,
+		"trying to unexport Foo... (impossible: re-exported by example.com/reexport/b)",
+		"trying to unexport Bar... (impossible: re-exported by example.com/reexport/b)",
+		"trying to unexport Baz... (impossible: re-exported by example.com/reexport/b)",
+		"trying to unexport Plain... (breaking: 1 references in other packages need a manual migration: ",
+	)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/quasilyte/go-unexport/internal/refs"
+)
+
+// printRegistryReport prints the exported symbols that are referenced
+// exactly once, as an argument of a registry function call:
+//
+//	registry.Register("foo", Foo{})
+//
+// Their exported-ness is often incidental, so they are worth reviewing.
+// This is a heuristic report: nothing is renamed.
+func (l *linter) printRegistryReport() error {
+	if l.flags.registryFuncs == "" {
+		return nil
+	}
+
+	funcs := make(map[string]bool)
+	for _, name := range strings.Split(l.flags.registryFuncs, ",") {
+		funcs[strings.TrimSpace(name)] = true
+	}
+	args := make(map[refs.Key]bool)
+	for _, pkg := range l.loaded {
+		for _, f := range pkg.Syntax {
+			l.collectRegistryArgs(f, funcs, args)
+		}
+	}
+
+	for _, sym := range l.symbols {
+		if !sym.ident.IsExported() || sym.obj == nil {
+			continue
+		}
+		list := l.usageRefs(sym)
+		if len(list) != 1 || !args[l.posKey(list[0].Ident.Pos())] {
+			continue
+		}
+		fmt.Printf("%s: %s is only referenced by a registry call at %s\n",
+			l.position(sym.ident.Pos()), sym.ident.Name, l.position(list[0].Ident.Pos()))
+	}
+
+	return errDone
+}
+
+// collectRegistryArgs adds the identifiers that are used in the
+// arguments of funcs calls to args.
+func (l *linter) collectRegistryArgs(f *ast.File, funcs map[string]bool, args map[refs.Key]bool) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var name string
+		switch fn := ast.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			name = fn.Name
+		case *ast.SelectorExpr:
+			name = fn.Sel.Name
+		}
+		if !funcs[name] {
+			return true
+		}
+		for _, arg := range call.Args {
+			ast.Inspect(arg, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					args[l.posKey(id.Pos())] = true
+				}
+				return true
+			})
+		}
+		return true
+	})
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/quasilyte/go-unexport/internal/refs"
+)
+
+// textEdit replaces [start, end) bytes of the file with newText.
+type textEdit struct {
+	filename string
+	start    int
+	end      int
+	newText  string
+}
+
+// renameInProcess renames sym without gorename.
+// With -edits, the rename is recorded as a suggested fix instead.
+func (l *linter) renameInProcess(sym *symbol, newName string) error {
+	if err := l.checkRename(sym, newName); err != nil {
+		return err
+	}
+	edits := l.renameEdits(sym, newName)
+	if l.needsShim(sym) {
+		shim, err := l.shimEdit(sym, newName)
+		if err != nil {
+			return err
+		}
+		edits = append(edits, shim)
+	}
+	l.markRenamed(sym, newName)
+	if l.flags.allowBreakingTests {
+		l.recordTestFiles(sym)
+	}
+	if l.flags.edits != "" {
+		l.addSuggestedFix(sym, fmt.Sprintf("rename %s to %s", sym.ident.Name, newName), edits)
+	} else {
+		l.pending = append(l.pending, edits...)
+	}
+	return nil
+}
+
+// checkRename is an in-process analog of the gorename safety checks.
+// It returns an error describing why sym can't be renamed to newName.
+func (l *linter) checkRename(sym *symbol, newName string) error {
+	if problems := l.renameProblems(sym, newName); len(problems) != 0 {
+		return problems[0]
+	}
+	return nil
+}
+
+// renameProblems returns all reasons why sym can't be renamed to newName.
+// Unlike gorename, it doesn't stop at the first one.
+func (l *linter) renameProblems(sym *symbol, newName string) []error {
+	if sym.obj == nil {
+		return []error{errors.New("no type info")}
+	}
+
+	var problems []error
+	if blocking := l.blockingRefs(sym); len(blocking) != 0 {
+		err := errors.New("would break package clients")
+		if platforms := l.blockingPlatforms(sym); len(platforms) != 0 {
+			err = fmt.Errorf("would break package clients on %s", strings.Join(platforms, ", "))
+		}
+		for _, ref := range blocking {
+			if l.refs.IsReexport(ref) {
+				err = fmt.Errorf("re-exported by %s", ref.Pkg.PkgPath)
+				break
+			}
+		}
+		if l.flags.deprecationShims {
+			if shimErr := l.checkShim(sym); shimErr != nil {
+				err = fmt.Errorf("%v (can't generate a shim: %v)", err, shimErr)
+			}
+		}
+		problems = append(problems, err)
+	}
+	if ref, ok := l.uneditableRef(sym); ok {
+		posn := l.position(ref.Ident.Pos())
+		problems = append(problems, fmt.Errorf("reference at %s can't be updated: not in the package source files", posn))
+	}
+	if l.requiredByInterface(sym) {
+		problems = append(problems, errors.New("would break interface assignability"))
+	}
+	if other := l.taken[l.scopeOf(sym)+"."+newName]; other != nil {
+		problems = append(problems, fmt.Errorf("conflicts with %s that is renamed to %s", other.ident.Name, newName))
+	}
+	if err := l.refs.Conflict(sym.obj, newName, l.isRenamed); err != nil {
+		problems = append(problems, err)
+	}
+	return problems
+}
+
+// requiredByInterface reports whether sym is a method
+// that can be used to satisfy some interface.
+func (l *linter) requiredByInterface(sym *symbol) bool {
+	if sym.requiredByInterface != nil {
+		return *sym.requiredByInterface
+	}
+	return l.refs.RequiredByInterface(sym.obj)
+}
+
+// blockingRefs returns sym external references that can't be broken.
+// Re-exports are never waived: they make sym a part of another package API.
+func (l *linter) blockingRefs(sym *symbol) []refs.Ref {
+	if l.needsShim(sym) {
+		return nil // External users get a deprecated wrapper
+	}
+	var blocking []refs.Ref
+	for _, ref := range l.refs.External(sym.obj) {
+		if l.refs.IsReexport(ref) {
+			blocking = append(blocking, ref)
+			continue
+		}
+		if l.flags.examples == "ignore" && l.isExampleRef(ref) {
+			continue
+		}
+		if l.flags.coordinateInternal && refs.InternalTree(sym.obj.Pkg().Path(), ref.Pkg.PkgPath) {
+			continue
+		}
+		if l.withinPaths != nil && l.isWithin(ref.Pkg.PkgPath) {
+			continue // Reported as broken, see brokenRefs
+		}
+		if l.allowedToBreak(ref.Pkg.PkgPath) {
+			continue
+		}
+		if l.flags.allowBreakingTests && l.isTestRef(ref) {
+			continue
+		}
+		blocking = append(blocking, ref)
+	}
+	return blocking
+}
+
+// usageRefs returns sym references except the ones inside of its
+// own declaration, like recursive calls or self-referencing fields.
+// They don't make sym used: they go away together with it.
+func (l *linter) usageRefs(sym *symbol) []refs.Ref {
+	var node ast.Node = sym.decl
+	if sym.spec != nil {
+		node = sym.spec
+	}
+	var usages []refs.Ref
+	for _, ref := range l.refs.Refs(sym.obj) {
+		if pos := ref.Ident.Pos(); pos >= node.Pos() && pos < node.End() {
+			continue
+		}
+		usages = append(usages, ref)
+	}
+	return usages
+}
+
+// brokenRefs returns sym external references that don't block
+// its rename due to a waiver like -coordinate-internal.
+// Go has no way to refer to an unexported name of another package,
+// so these references are broken by the rename.
+func (l *linter) brokenRefs(sym *symbol) []refs.Ref {
+	if sym.obj == nil || l.needsShim(sym) {
+		return nil
+	}
+	return l.refs.External(sym.obj)
+}
+
+// describeBroken returns a status text for the rename that breaks refs.
+func (l *linter) describeBroken(broken []refs.Ref) string {
+	positions := make([]string, len(broken))
+	for i, ref := range broken {
+		positions[i] = l.position(ref.Ident.Pos()).String()
+	}
+	return fmt.Sprintf("%d references in other packages need a manual migration: %s",
+		len(broken), strings.Join(l.limitReport(positions), ", "))
+}
+
+// isTestRef reports whether ref is located in a _test.go file.
+func (l *linter) isTestRef(ref refs.Ref) bool {
+	return strings.HasSuffix(l.fset.Position(ref.Ident.Pos()).Filename, "_test.go")
+}
+
+// recordTestFiles adds the other packages test files
+// that reference sym to the broken test files set.
+func (l *linter) recordTestFiles(sym *symbol) {
+	for _, ref := range l.refs.External(sym.obj) {
+		if !l.isTestRef(ref) {
+			continue
+		}
+		if l.testFiles == nil {
+			l.testFiles = make(map[string]bool)
+		}
+		l.testFiles[l.fset.Position(ref.Ident.Pos()).Filename] = true
+	}
+}
+
+// uneditableRef returns a sym reference that can't be renamed in place.
+//
+// All kinds of references, like method values and method expressions,
+// are recorded by the type checker, but some of them may come from
+// the files that are not a part of the package sources: cgo-processed
+// files live in the build cache and editing them changes nothing.
+func (l *linter) uneditableRef(sym *symbol) (refs.Ref, bool) {
+	for _, ref := range l.refs.Refs(sym.obj) {
+		filename := l.fset.Position(ref.Ident.Pos()).Filename
+		if !containsString(ref.Pkg.GoFiles, filename) {
+			return ref, true
+		}
+	}
+	return refs.Ref{}, false
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedToBreak reports whether pkgPath is under any of
+// the -allow-breaking-prefix import paths. Its references
+// are left broken, see brokenRefs.
+func (l *linter) allowedToBreak(pkgPath string) bool {
+	pkgPath = strings.TrimSuffix(pkgPath, "_test")
+	for _, prefix := range splitList(l.flags.allowBreakingPrefix) {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linter) isExampleRef(ref refs.Ref) bool {
+	posn := l.fset.Position(ref.Ident.Pos())
+	for _, r := range l.examples[posn.Filename] {
+		if posn.Offset >= r[0] && posn.Offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// renameEdits returns the edits that rename sym declaration
+// and all of its references to newName.
+//
+// References from other packages are renamed too. They can only
+// be there if their blocking was waived, so they need to be fixed
+// by the user; renaming them makes the build errors point to them.
+// Such renames are reported as breaking, see brokenRefs.
+// The exception are the symbols that get a deprecation shim: their
+// external references are kept to use the shim.
+func (l *linter) renameEdits(sym *symbol, newName string) []textEdit {
+	shim := l.needsShim(sym)
+	edits := []textEdit{l.identEdit(sym.ident.Pos(), sym.ident.Name, newName)}
+	for _, ref := range l.refs.Refs(sym.obj) {
+		if shim && ref.Pkg.PkgPath != sym.obj.Pkg().Path() {
+			continue
+		}
+		edits = append(edits, l.identEdit(ref.Ident.Pos(), ref.Ident.Name, newName))
+	}
+	return edits
+}
+
+func (l *linter) identEdit(pos token.Pos, oldName, newName string) textEdit {
+	posn := l.fset.Position(pos)
+	return textEdit{
+		filename: posn.Filename,
+		start:    posn.Offset,
+		end:      posn.Offset + len(oldName),
+		newText:  newName,
+	}
+}
+
+func (l *linter) applyEdits() error {
+	byFile := make(map[string][]textEdit)
+	for _, e := range l.pending {
+		byFile[e.filename] = append(byFile[e.filename], e)
+	}
+
+	for filename, edits := range byFile {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		// Applying edits from the end keeps the other offsets valid.
+		sort.Slice(edits, func(i, j int) bool {
+			return edits[i].start > edits[j].start
+		})
+		deletes := false
+		for _, e := range edits {
+			data = append(data[:e.start:e.start], append([]byte(e.newText), data[e.end:]...)...)
+			deletes = deletes || (e.newText == "" && e.end != e.start)
+		}
+		if deletes {
+			// Deleted declarations leave the blank lines
+			// around them, gofmt squashes them.
+			if formatted, err := format.Source(data); err == nil {
+				data = formatted
+			}
+		}
+		if err := os.WriteFile(filename, data, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
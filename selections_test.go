@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestSelectionConflicts(t *testing.T) {
+	dir := copyFixture(t, "selections")
+	out := runTool(t, dir, "-renamer=inprocess", "-unexport", "Foo,Bar,Baz", "./...")
+	wantLines(t, out,
+		"trying to unexport Foo... (impossible: reference at ",
+		"p/p.go:13:30 would select field foo int)",
+		"trying to unexport Bar... (impossible: selection of field bar int at ",
+		"p/p.go:29:30 would select the renamed Bar)",
+		"trying to unexport Baz... (success)",
+	)
+	checkBuild(t, dir)
+}
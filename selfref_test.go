@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelfReferences(t *testing.T) {
+	// The references inside of the symbol own
+	// declaration don't make it used.
+	dir := copyFixture(t, "selfref")
+	out := runTool(t, dir, "-renamer=inprocess", "-delete-dead", "./...")
+	wantLines(t, out,
+		"trying to unexport Fact... (deleted)",
+		"trying to unexport Node... (deleted)",
+		"trying to unexport Walk... (success)",
+	)
+	src := readFile(t, dir, "p/p.go")
+	for _, name := range []string{"Fact", "Node"} {
+		if strings.Contains(src, name) {
+			t.Errorf("%s is not deleted:\n%s", name, src)
+		}
+	}
+	if !strings.Contains(src, "return walk(n - 1)") {
+		t.Errorf("the recursive call is not renamed:\n%s", src)
+	}
+	checkBuild(t, dir)
+}
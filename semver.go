@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// semverBlock returns a reason why unexporting sym would violate the
+// module semver guarantees, or an empty string if it's fine.
+//
+// Only the packages that other modules can import are considered:
+// main and internal packages are not a part of the module API.
+// Modules that are v0 or have no version at all make no compatibility
+// promises, so their API can change freely.
+func (l *linter) semverBlock(sym *symbol) string {
+	if !l.flags.semver || l.flags.allowBreakingMajor {
+		return ""
+	}
+	pkg := sym.pkg
+	if pkg.Module == nil || pkg.Name == "main" || l.inTestFile(sym) || isInternal(pkg) {
+		return ""
+	}
+	version := l.moduleVersion(pkg.Module)
+	if version == "" || strings.HasPrefix(version, "v0.") {
+		return ""
+	}
+	major := modulePathMajor(pkg.Module.Path)
+	return fmt.Sprintf("breaking change for %s module API, needs a new major version (v%d) and -allow-breaking-major",
+		pkg.Module.Path, major+1)
+}
+
+// moduleVersion returns the version of mod or an empty string if it has none.
+//
+// The main modules have no version of their own, it's taken from the module
+// path major suffix or, without one, from the latest tag of its git repository.
+// Tags of the nested modules are prefixed with their directory, like sub/v1.2.0.
+func (l *linter) moduleVersion(mod *packages.Module) string {
+	if mod.Version != "" {
+		return mod.Version
+	}
+	if version, ok := l.moduleVersions[mod.Path]; ok {
+		return version
+	}
+
+	version := ""
+	if major := modulePathMajor(mod.Path); major >= 2 {
+		version = fmt.Sprintf("v%d.0.0", major)
+	} else if mod.Dir != "" {
+		version = latestGitTag(mod.Dir)
+	}
+	if l.moduleVersions == nil {
+		l.moduleVersions = make(map[string]string)
+	}
+	l.moduleVersions[mod.Path] = version
+	return version
+}
+
+// latestGitTag returns the greatest semver tag of the module in dir
+// or an empty string if there is none, or dir is not in a git repository.
+func latestGitTag(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return ""
+	}
+	prefix := strings.TrimSpace(string(out))
+	out, err = exec.Command("git", "-C", dir, "tag", "--list", prefix+"v*", "--sort=-v:refname").Output()
+	if err != nil {
+		return ""
+	}
+	for _, tag := range strings.Fields(string(out)) {
+		version := strings.TrimPrefix(tag, prefix)
+		if isSemver(version) {
+			return version
+		}
+	}
+	return ""
+}
+
+// isSemver reports whether version looks like vMAJOR.MINOR.PATCH,
+// optionally followed by a pre-release or build suffix.
+func isSemver(version string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) != 3 || !strings.HasPrefix(version, "v") {
+		return false
+	}
+	if i := strings.IndexAny(parts[2], "-+"); i != -1 {
+		parts[2] = parts[2][:i]
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func isInternal(pkg *packages.Package) bool {
+	path := strings.TrimSuffix(pkg.PkgPath, "_test")
+	return strings.HasSuffix(path, "/internal") || strings.Contains(path, "/internal/")
+}
+
+// modulePathMajor returns the major version encoded in the module path,
+// like 2 for example.com/lib/v2 and gopkg.in/yaml.v2.
+// Module paths without a suffix are v0 or v1, 1 is returned for them.
+func modulePathMajor(modPath string) int {
+	i := strings.LastIndexAny(modPath, "/.")
+	if i == -1 || i+2 >= len(modPath) || modPath[i+1] != 'v' {
+		return 1
+	}
+	n, err := strconv.Atoi(modPath[i+2:])
+	if err != nil || n < 2 {
+		return 1
+	}
+	return n
+}
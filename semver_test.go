@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSemverFeasibility(t *testing.T) {
+	dir := copyFixture(t, "semver")
+	gitTag(t, dir, "v1.2.0")
+	const reason = "breaking change for example.com/semver module API, needs a new major version (v2) and -allow-breaking-major"
+
+	out, _ := runToolErr(t, dir, "check", "-semver", "./...")
+	wantLines(t, out, reason)
+
+	out = runTool(t, dir, "-doc-preview", "-semver", "./...")
+	wantLines(t, out, "func Foo()")
+
+	out, err := runToolErr(t, dir, "-renamer=inprocess", "-only-if-all-feasible", "-semver", "./...")
+	if err == nil {
+		t.Fatalf("-only-if-all-feasible passed:\n%s", out)
+	}
+	wantLines(t, out, reason, "nothing is changed")
+	if src := readFile(t, dir, "lib/lib.go"); !strings.Contains(src, "func Foo()") {
+		t.Errorf("lib.go is changed:\n%s", src)
+	}
+}
+
+func TestSemverUnversioned(t *testing.T) {
+	for _, tag := range []string{"", "v0.3.0"} {
+		t.Run("tag="+tag, func(t *testing.T) {
+			dir := copyFixture(t, "semver")
+			if tag != "" {
+				gitTag(t, dir, tag)
+			}
+			out, _ := runToolErr(t, dir, "check", "-semver", "./...")
+			wantLines(t, out, "Foo: feasible")
+			unwantLines(t, out, "breaking change")
+		})
+	}
+}
+
+func TestModulePathMajor(t *testing.T) {
+	dir := copyFixture(t, "semver")
+	gomod := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(gomod, []byte("module example.com/semver/v2\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := runToolErr(t, dir, "check", "-semver", "./...")
+	wantLines(t, out, "breaking change for example.com/semver/v2 module API, needs a new major version (v3)")
+}
+
+// gitTag makes dir a git repository with a single commit tagged as tag.
+func gitTag(t *testing.T, dir, tag string) {
+	t.Helper()
+	commands := [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+		{"tag", tag},
+	}
+	for _, args := range commands {
+		if out, err := goCommand(dir, "git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
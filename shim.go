@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+)
+
+// needsShim reports whether sym is renamed with a deprecated
+// exported wrapper left for its external users. See shimEdit.
+func (l *linter) needsShim(sym *symbol) bool {
+	return l.flags.deprecationShims && len(l.refs.External(sym.obj)) != 0 && l.checkShim(sym) == nil
+}
+
+// checkShim returns an error describing why sym can't get a shim.
+// Only simple functions and methods are supported.
+func (l *linter) checkShim(sym *symbol) error {
+	decl, ok := sym.decl.(*ast.FuncDecl)
+	if !ok {
+		return fmt.Errorf("%s is not a func", sym.kind)
+	}
+	if l.inTestFile(sym) {
+		return errors.New("declared in a test file")
+	}
+	if decl.Type.TypeParams != nil {
+		return errors.New("generic funcs are not supported")
+	}
+	if decl.Recv != nil {
+		fn, ok := sym.obj.(*types.Func)
+		if !ok {
+			return errors.New("no type info")
+		}
+		typ := fn.Type().(*types.Signature).Recv().Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if named, ok := typ.(*types.Named); !ok || named.TypeParams().Len() != 0 {
+			return errors.New("generic receivers are not supported")
+		}
+	}
+	if !namedFields(decl.Type.Params) {
+		return errors.New("all params must be named")
+	}
+	if newName := l.unexportedName(sym); decl.Recv == nil && fieldsHaveName(newName, decl.Type.Params, decl.Type.Results) {
+		// The shim would call the param instead of the renamed func.
+		return fmt.Errorf("a param or result is named %s", newName)
+	}
+	return nil
+}
+
+// fieldsHaveName reports whether any of the lists has a field named name.
+func fieldsHaveName(name string, lists ...*ast.FieldList) bool {
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, id := range field.Names {
+				if id.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// namedFields reports whether every field in list has a non-blank name.
+func namedFields(list *ast.FieldList) bool {
+	for _, field := range list.List {
+		if len(field.Names) == 0 {
+			return false
+		}
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// shimRecvName returns a receiver name that doesn't clash
+// with decl params and results.
+func shimRecvName(decl *ast.FuncDecl) string {
+	name := "recv"
+	for i := 2; fieldsHaveName(name, decl.Type.Params, decl.Type.Results); i++ {
+		name = fmt.Sprintf("recv%d", i)
+	}
+	return name
+}
+
+// shimEdit returns an edit that inserts a deprecated exported
+// wrapper that forwards to sym renamed to newName:
+//
+//	// Deprecated: Foo is not a part of the package API anymore.
+//	func Foo(x int) int {
+//		return foo(x)
+//	}
+func (l *linter) shimEdit(sym *symbol, newName string) (textEdit, error) {
+	decl := sym.decl.(*ast.FuncDecl)
+	start := l.fset.Position(decl.Pos())
+	end := l.fset.Position(decl.End())
+	src, err := os.ReadFile(start.Filename)
+	if err != nil {
+		return textEdit{}, err
+	}
+	text := func(from, to token.Pos) string {
+		return string(src[l.fset.Position(from).Offset:l.fset.Position(to).Offset])
+	}
+
+	var args []string
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			args = append(args, name.Name)
+		}
+	}
+	if len(args) != 0 {
+		if _, ok := decl.Type.Params.List[len(decl.Type.Params.List)-1].Type.(*ast.Ellipsis); ok {
+			args[len(args)-1] += "..."
+		}
+	}
+
+	var buf strings.Builder
+	name := sym.ident.Name
+	fmt.Fprintf(&buf, "\n\n// Deprecated: %s is not a part of the package API anymore.\nfunc ", name)
+	call := newName
+	if decl.Recv != nil {
+		// Blank and unnamed receivers get a name, the type
+		// is taken from the declaration as is.
+		field := decl.Recv.List[0]
+		recvName := shimRecvName(decl)
+		if len(field.Names) != 0 && field.Names[0].Name != "_" {
+			recvName = field.Names[0].Name
+		}
+		fmt.Fprintf(&buf, "(%s %s) ", recvName, text(field.Type.Pos(), field.Type.End()))
+		call = recvName + "." + newName
+	}
+	buf.WriteString(name)
+	buf.WriteString(text(decl.Type.Params.Pos(), decl.Type.End()))
+	buf.WriteString(" {\n\t")
+	if decl.Type.Results != nil {
+		buf.WriteString("return ")
+	}
+	fmt.Fprintf(&buf, "%s(%s)\n}", call, strings.Join(args, ", "))
+
+	return textEdit{
+		filename: end.Filename,
+		start:    end.Offset,
+		end:      end.Offset,
+		newText:  buf.String(),
+	}, nil
+}
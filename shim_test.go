@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShims(t *testing.T) {
+	dir := copyFixture(t, "shims")
+	out := runTool(t, dir, "-renamer=inprocess", "-deprecation-shims", "-v",
+		"-json-summary", filepath.Join(dir, "summary.json"), "-unexport", "Size,Double,Recv", "./...")
+	wantLines(t, out,
+		"trying to unexport Size... (impossible: would break package clients (can't generate a shim: a param or result is named size))",
+		"trying to unexport Double... (shimmed)",
+		"trying to unexport Recv... (shimmed)",
+		"deprecated, kept exported by -deprecation-shims:",
+		"/Double: Double -> double",
+		"(5 -> 5)",
+	)
+	// The shims keep the API, nothing is removed.
+	unwantLines(t, out, "unexported:", "changed packages:", "removed")
+	for _, r := range readSummary(t, dir, "summary.json") {
+		if !r.Shimmed {
+			t.Errorf("%s rename is not marked as shimmed: %+v", r.Old, r)
+		}
+	}
+	src := readFile(t, dir, "lib/lib.go")
+	if !strings.Contains(src, "func (recv2 T) Recv() (recv int) {\n\treturn recv2.recv()\n}") {
+		t.Errorf("unexpected Recv shim:\n%s", src)
+	}
+	checkBuild(t, dir)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// isStable reports whether sym is marked by the -stability-comment
+// directive, like //go:api stable. The directive can be either in the
+// declaration doc comment or in the file header comments, before the
+// package clause; the latter marks all file symbols.
+func (l *linter) isStable(sym *symbol) bool {
+	if l.flags.stabilityComment == "" {
+		return false
+	}
+
+	var docs []*ast.CommentGroup
+	switch decl := sym.decl.(type) {
+	case *ast.FuncDecl:
+		docs = append(docs, decl.Doc)
+	case *ast.GenDecl:
+		docs = append(docs, decl.Doc)
+		switch spec := sym.spec.(type) {
+		case *ast.ValueSpec:
+			docs = append(docs, spec.Doc)
+		case *ast.TypeSpec:
+			docs = append(docs, spec.Doc)
+		}
+	}
+	for _, f := range sym.pkg.Syntax {
+		if f.FileStart <= sym.ident.Pos() && sym.ident.Pos() < f.FileEnd {
+			for _, group := range f.Comments {
+				if group.Pos() < f.Package {
+					docs = append(docs, group)
+				}
+			}
+			break
+		}
+	}
+
+	for _, doc := range docs {
+		if doc != nil && l.hasStabilityComment(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linter) hasStabilityComment(doc *ast.CommentGroup) bool {
+	directive := strings.TrimPrefix(l.flags.stabilityComment, "//")
+	for _, c := range doc.List {
+		text := strings.TrimPrefix(c.Text, "//")
+		if text == directive || strings.HasPrefix(text, directive+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// resolveSubtree replaces the targets with the -subtree packages
+// and the packages of the same module that depend on them, directly
+// or transitively, including their tests. Only the -subtree packages
+// are processed, the dependents are loaded to check the references.
+//
+// The dependents are found with "go list" that doesn't parse
+// or type check anything, so it stays fast even for a huge module;
+// only the packages that can reference the subtree symbols
+// are loaded with the full type information.
+func (l *linter) resolveSubtree() error {
+	root, err := filepath.Abs(l.flags.subtree)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "list", "-m", "-f", "{{.Dir}}")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	moduleDirs := strings.Fields(string(out))
+	if len(moduleDirs) != 1 {
+		return errors.New("-subtree requires a single main module")
+	}
+
+	const format = `{{.ImportPath}}	{{.Dir}}	{{join .Imports " "}}	{{join .TestImports " "}} {{join .XTestImports " "}}`
+	cmd = exec.Command("go", "list", "-e", "-f", format, "./...")
+	cmd.Dir = moduleDirs[0]
+	out, err = cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	type listedPackage struct {
+		path        string
+		imports     []string // Without the test imports
+		testImports []string
+	}
+	var listed []listedPackage
+	l.subtreePaths = make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		path, dir := fields[0], fields[1]
+		if dir == root || strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			l.subtreePaths[path] = true
+		}
+		listed = append(listed, listedPackage{
+			path:        path,
+			imports:     strings.Fields(fields[2]),
+			testImports: strings.Fields(fields[3]),
+		})
+	}
+	if len(l.subtreePaths) == 0 {
+		return errors.New("no packages found in -subtree")
+	}
+
+	// A package can use the subtree methods and fields without
+	// importing the subtree, through a package in between:
+	//
+	//	b.Get().Foo() // b.Get returns a subtree type
+	//
+	// So all packages that depend on the subtree are needed.
+	// Tests can't be imported, so only their own packages are added.
+	depends := make(map[string]bool, len(l.subtreePaths))
+	for path := range l.subtreePaths {
+		depends[path] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, pkg := range listed {
+			if !depends[pkg.path] && anyOf(pkg.imports, depends) {
+				depends[pkg.path] = true
+				changed = true
+			}
+		}
+	}
+
+	l.flags.targets = nil
+	for _, pkg := range listed {
+		if depends[pkg.path] || anyOf(pkg.testImports, depends) {
+			l.flags.targets = append(l.flags.targets, pkg.path)
+		}
+	}
+	return nil
+}
+
+// anyOf reports whether any of the list elements is in set.
+func anyOf(list []string, set map[string]bool) bool {
+	for _, s := range list {
+		if set[s] {
+			return true
+		}
+	}
+	return false
+}
+
+// inSubtree reports whether pkgPath is a -subtree package.
+// Without -subtree, all loaded packages are.
+func (l *linter) inSubtree(pkgPath string) bool {
+	return l.subtreePaths == nil || l.subtreePaths[strings.TrimSuffix(pkgPath, "_test")]
+}
@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestSubtreeTransitiveDependents(t *testing.T) {
+	dir := copyFixture(t, "subtree")
+	out := runTool(t, dir, "-renamer=inprocess", "-subtree", "a")
+	wantLines(t, out,
+		"trying to unexport Foo... (impossible: would break package clients)",
+		"trying to unexport Bar... (impossible: would break package clients)",
+		"trying to unexport Unused... (success)",
+	)
+	unwantLines(t, out, "trying to unexport Get")
+	checkBuild(t, dir)
+}
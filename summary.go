@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// jsonSummary is a machine-readable form of the run results.
+type jsonSummary struct {
+	ExportedBefore   int     `json:"exported_before"`
+	ExportedAfter    int     `json:"exported_after"`
+	ReductionPercent float64 `json:"reduction_percent"`
+
+	Renames         []jsonRename         `json:"renames"`
+	ChangedPackages []jsonChangedPackage `json:"changed_packages"`
+	Skipped         []jsonSkipped        `json:"skipped,omitempty"`
+}
+
+// jsonSkipped is an exported symbol that was not a candidate.
+type jsonSkipped struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Offset   int    `json:"offset"`
+	Name     string `json:"name"`
+	Reason   string `json:"reason"`
+}
+
+// jsonChangedPackage is a package which exported API was changed.
+type jsonChangedPackage struct {
+	Path    string `json:"path"`
+	Removed int    `json:"removed"`
+}
+
+// jsonRename describes a single performed rename.
+// The position is split into fields, so file names
+// that contain colons can't be misinterpreted.
+//
+// Breaking renames are the ones that were allowed by the flags
+// like -coordinate-internal: BrokenRefs positions in the other
+// packages refer to the unexported name now and don't compile.
+type jsonRename struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Offset   int    `json:"offset"`
+	Old      string `json:"old"`
+	New      string `json:"new,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+	Group    string `json:"group,omitempty"`
+
+	// Shimmed renames keep a deprecated exported wrapper
+	// under the old name, see -deprecation-shims.
+	Shimmed bool `json:"shimmed,omitempty"`
+
+	Breaking   bool     `json:"breaking,omitempty"`
+	BrokenRefs []string `json:"broken_refs,omitempty"`
+}
+
+// changedPackages returns the packages with at least one exported
+// symbol removed, sorted by path. Test packages are not included.
+func (l *linter) changedPackages() []jsonChangedPackage {
+	var list []jsonChangedPackage
+	for path, n := range l.changed {
+		if n != 0 && !strings.HasSuffix(path, "_test") {
+			list = append(list, jsonChangedPackage{Path: path, Removed: n})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
+
+func (l *linter) writeSummary() error {
+	if l.flags.jsonSummary == "" {
+		return nil
+	}
+
+	summary := jsonSummary{
+		ExportedBefore:   l.exportedBefore,
+		ExportedAfter:    l.exportedBefore - l.exportedRemoved,
+		ReductionPercent: l.reductionPercent(),
+		Renames:          l.renames,
+		ChangedPackages:  l.changedPackages(),
+		Skipped:          l.skipped,
+	}
+	sort.Slice(summary.Renames, func(i, j int) bool {
+		x, y := summary.Renames[i], summary.Renames[j]
+		if x.Filename != y.Filename {
+			return x.Filename < y.Filename
+		}
+		return x.Offset < y.Offset
+	})
+	data, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.flags.jsonSummary, data, 0644)
+}
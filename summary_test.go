@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestRenamePositionFields(t *testing.T) {
+	// A Windows file name has a colon after the drive letter,
+	// the position fields are not affected by it.
+	var l linter
+	l.fset = token.NewFileSet()
+	file := l.fset.AddFile(`D:\Desktop\proj\a.go`, -1, 100)
+	file.SetLines([]int{0, 20, 40})
+	ident := &ast.Ident{Name: "Foo", NamePos: file.Pos(45)}
+	l.addRename(&symbol{ident: ident}, "foo")
+
+	data, err := json.Marshal(l.renames[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r jsonRename
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	want := jsonRename{
+		Filename: `D:\Desktop\proj\a.go`,
+		Line:     3,
+		Column:   6,
+		Offset:   45,
+		Old:      "Foo",
+		New:      "foo",
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("have %+v\nwant %+v", r, want)
+	}
+}
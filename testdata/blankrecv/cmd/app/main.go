@@ -0,0 +1,8 @@
+package main
+
+import "example.com/blankrecv/p"
+
+func main() {
+	println(p.Describe(p.T{}))
+	println(new(p.U).Name())
+}
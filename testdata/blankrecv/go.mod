@@ -0,0 +1,3 @@
+module example.com/blankrecv
+
+go 1.21
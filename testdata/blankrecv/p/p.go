@@ -0,0 +1,17 @@
+package p
+
+type Stringer interface{ String() string }
+
+func Describe(s Stringer) string { return s.String() }
+
+type T struct{}
+
+func (_ T) String() string { return "T" }
+
+func (_ T) Name() string { return "t" }
+
+type U struct{}
+
+func (_ *U) Name() string { return "u" }
+
+func (*U) Size() int { return 0 }
@@ -0,0 +1,5 @@
+//go:build ignore
+
+package ignored
+
+func F() {}
@@ -0,0 +1,11 @@
+package p
+
+func A() {}
+
+func B() {}
+
+func C() {}
+
+type T struct{}
+
+func (T) B() {}
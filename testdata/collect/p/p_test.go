@@ -0,0 +1,5 @@
+package p
+
+import "testing"
+
+func TestA(t *testing.T) { A() }
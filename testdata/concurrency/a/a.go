@@ -0,0 +1,7 @@
+package a
+
+func Local() int { return 1 }
+
+func UsedByTest() int { return Local() }
+
+func UsedByB() int { return 2 }
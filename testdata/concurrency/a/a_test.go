@@ -0,0 +1,13 @@
+package a_test
+
+import (
+	"testing"
+
+	"example.com/concurrency/a"
+)
+
+func TestA(t *testing.T) {
+	if a.UsedByTest() != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,5 @@
+package b
+
+import "example.com/concurrency/a"
+
+func Local() int { return a.UsedByB() }
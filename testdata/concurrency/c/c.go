@@ -0,0 +1,7 @@
+package c
+
+type T struct{}
+
+func (T) Method() {}
+
+func Local() { T{}.Method() }
@@ -0,0 +1,27 @@
+package lib
+
+type point struct{ x, y int }
+
+var ch = make(chan int, 1)
+
+var ptr = new(int)
+
+var arr = []int{1}
+
+var Recv = <-ch
+
+var Deref = *ptr
+
+var Index = arr[0]
+
+var Call = len(arr)
+
+const Shift = 1 << 3
+
+var Composite = map[string]point{"a": {x: 1, y: Shift}}
+
+var Addr = &point{x: 1}
+
+func Unused() {}
+
+func Use() {}
@@ -0,0 +1,5 @@
+package lib
+
+import "testing"
+
+func TestUse(t *testing.T) { Use() }
@@ -0,0 +1,11 @@
+package p
+
+type A struct{}
+
+func (A) Name() string { return "a" }
+
+type B struct{}
+
+func (*B) Name() string { return "b" }
+
+func Use() string { return A{}.Name() + new(B).Name() }
@@ -0,0 +1,9 @@
+package p
+
+import "testing"
+
+func TestUse(t *testing.T) {
+	if Use() != "ab" {
+		t.Fail()
+	}
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"example.com/embedded/p"
+	"example.com/embedded/q"
+)
+
+func main() {
+	println(p.Use() + q.Use())
+}
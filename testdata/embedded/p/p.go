@@ -0,0 +1,13 @@
+package p
+
+type T struct{ X int }
+
+type S struct{ T }
+
+func Use() int {
+	v := S{T: T{X: 1}}
+	return v.T.X
+}
+
+// Init would become a package initializer.
+func Init() {}
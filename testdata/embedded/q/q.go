@@ -0,0 +1,15 @@
+package q
+
+type T struct{}
+
+// The embedded field can't become t.
+type U struct {
+	T
+	t int
+}
+
+func Use() int {
+	u := U{T: T{}, t: 1}
+	_ = u.T
+	return u.t
+}
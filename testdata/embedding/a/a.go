@@ -0,0 +1,11 @@
+package a
+
+type T struct{}
+
+// Foo is only required by b.FooBazer through b.Wrapper.
+func (T) Foo() {}
+
+// Bar is only called through b.Wrapper.
+func (T) Bar() {}
+
+func (T) Qux() {}
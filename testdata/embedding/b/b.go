@@ -0,0 +1,14 @@
+package b
+
+import "example.com/embedding/a"
+
+type Wrapper struct{ a.T }
+
+func (Wrapper) Baz() {}
+
+type FooBazer interface {
+	Foo()
+	Baz()
+}
+
+var _ FooBazer = Wrapper{}
@@ -0,0 +1,8 @@
+package main
+
+import "example.com/embedding/b"
+
+func main() {
+	var w b.Wrapper
+	w.Bar()
+}
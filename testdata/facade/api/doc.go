@@ -0,0 +1,2 @@
+// Package api is the public facade of the library.
+package api
@@ -0,0 +1,31 @@
+package impl
+
+import "context"
+
+const Version = "1.0"
+
+type Client struct{ addr string }
+
+type Option func(*Client)
+
+// Helper is not kept, so it's unexported
+// and NewDebug can't be re-exported.
+type Helper struct{}
+
+type state int
+
+func NewClient(ctx context.Context, impl string, opts ...Option) (*Client, error) {
+	c := &Client{addr: impl}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, ctx.Err()
+}
+
+func Close(*Client, int) {}
+
+func NewDebug() *Helper { return &Helper{} }
+
+func Inspect(s state) {}
+
+var Default = &Client{}
@@ -0,0 +1,3 @@
+package other
+
+const Version = "2.0"
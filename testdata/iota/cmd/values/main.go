@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/iota/consts"
+)
+
+func main() {
+	fmt.Println(consts.B, consts.C, consts.X, consts.Z, consts.Values())
+}
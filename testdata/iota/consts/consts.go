@@ -0,0 +1,16 @@
+package consts
+
+// A is only used inside of this package, B and C are used by cmd/values.
+const (
+	A = 1 << iota
+	B
+	C
+)
+
+const (
+	X = iota * 10
+	Y
+	Z
+)
+
+func Values() []int { return []int{A, B, C, X, Y, Z} }
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/methodrefs/p"
+
+func main() {
+	println(p.Run())
+}
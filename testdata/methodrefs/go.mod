@@ -0,0 +1,3 @@
+module example.com/methodrefs
+
+go 1.21
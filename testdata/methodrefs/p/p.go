@@ -0,0 +1,21 @@
+package p
+
+type T struct{}
+
+func (T) Value() int { return 1 }
+
+func (T) Expr() int { return 2 }
+
+type Caller interface{ Call() int }
+
+type impl struct{}
+
+func (impl) Call() int { return 3 }
+
+func Run() int {
+	var t T
+	f := t.Value
+	g := T.Expr
+	var c Caller = impl{}
+	return f() + g(t) + c.Call()
+}
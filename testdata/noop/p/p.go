@@ -0,0 +1,6 @@
+package p
+
+// ϒ is an upper case letter without a lower case form.
+func ϒFoo() {}
+
+func Other() {}
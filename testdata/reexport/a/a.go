@@ -0,0 +1,9 @@
+package a
+
+func Foo() {}
+
+type Bar struct{}
+
+const Baz = 1
+
+func Plain() {}
@@ -0,0 +1,13 @@
+package b
+
+import "example.com/reexport/a"
+
+var Foo = a.Foo
+
+type Bar = a.Bar
+
+const Baz = a.Baz
+
+func init() {
+	a.Plain()
+}
@@ -0,0 +1,5 @@
+package b
+
+import "example.com/fx/a"
+
+func Get() a.T { return a.T{} }
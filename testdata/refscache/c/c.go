@@ -0,0 +1,5 @@
+package c
+
+import "example.com/fx/b"
+
+func C() int { return b.Get().Foo() }
@@ -0,0 +1,3 @@
+module example.com/selections
+
+go 1.21
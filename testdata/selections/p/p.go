@@ -0,0 +1,35 @@
+package p
+
+type T struct{}
+
+func (T) Foo() int { return 1 }
+
+// U has its own foo, so U{}.Foo() can't become U{}.foo().
+type U struct {
+	T
+	foo int
+}
+
+func useU() int { return U{}.Foo() + U{}.foo }
+
+type V struct{}
+
+func (V) Bar() int { return 2 }
+
+type inner struct{ bar int }
+
+type mid struct{ inner }
+
+// W selects inner.bar, the renamed V.bar would take it over.
+type W struct {
+	V
+	mid
+}
+
+func useW() int { return W{}.bar + V{}.Bar() }
+
+type S struct{}
+
+func (S) Baz() int { return 3 }
+
+func useS() int { return S{}.Baz() }
@@ -0,0 +1,3 @@
+module example.com/selfref
+
+go 1.21
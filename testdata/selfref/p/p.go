@@ -0,0 +1,24 @@
+package p
+
+// Fact is only used by itself.
+func Fact(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n * Fact(n-1)
+}
+
+// Node is only used by itself.
+type Node struct {
+	Next *Node
+}
+
+// Walk is used by itself and by the blank var.
+func Walk(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return Walk(n - 1)
+}
+
+var _ = Walk(3)
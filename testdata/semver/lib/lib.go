@@ -0,0 +1,4 @@
+package lib
+
+// Foo is not used anywhere, but it's a part of the module API.
+func Foo() {}
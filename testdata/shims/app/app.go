@@ -0,0 +1,5 @@
+package app
+
+import "example.com/shims/lib"
+
+func Use() int { return lib.Size(1) + lib.Double(2) + lib.T{}.Recv() }
@@ -0,0 +1,3 @@
+module example.com/shims
+
+go 1.21
@@ -0,0 +1,11 @@
+package lib
+
+// Size param would shadow the renamed func in the shim.
+func Size(size int) int { return size * 2 }
+
+func Double(x int) int { return x * 2 }
+
+type T struct{}
+
+// Recv result would clash with the generated shim receiver name.
+func (T) Recv() (recv int) { return 1 }
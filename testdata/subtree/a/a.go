@@ -0,0 +1,9 @@
+package a
+
+type T struct{}
+
+func (T) Foo() int { return 1 }
+
+func (T) Bar() int { return 2 }
+
+func Unused() {}
@@ -0,0 +1,7 @@
+package b
+
+import "example.com/subtree/a"
+
+func Get() a.T { return a.T{} }
+
+func Bar() int { return Get().Bar() }
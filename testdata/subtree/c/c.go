@@ -0,0 +1,6 @@
+package c
+
+import "example.com/subtree/b"
+
+// C uses a.T method without importing a.
+func C() int { return b.Get().Foo() }
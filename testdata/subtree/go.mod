@@ -0,0 +1,3 @@
+module example.com/subtree
+
+go 1.21
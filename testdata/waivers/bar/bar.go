@@ -0,0 +1,5 @@
+package bar
+
+import "example.com/waivers/internal/x"
+
+func Baz() int { return x.Foo() }
@@ -0,0 +1,3 @@
+module example.com/waivers
+
+go 1.21
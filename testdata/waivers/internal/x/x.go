@@ -0,0 +1,5 @@
+package x
+
+func Foo() int { return 1 }
+
+func Bar() int { return Foo() }
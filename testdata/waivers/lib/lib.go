@@ -0,0 +1,3 @@
+package lib
+
+func Lib() int { return 1 }
@@ -0,0 +1,13 @@
+package lib_test
+
+import (
+	"testing"
+
+	"example.com/waivers/lib"
+)
+
+func TestLib(t *testing.T) {
+	if lib.Lib() != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,3 @@
+package app
+
+func Answer() int { return 42 }
@@ -0,0 +1,13 @@
+package app
+
+import (
+	"testing"
+
+	"example.com/lib"
+)
+
+func TestAnswer(t *testing.T) {
+	if Answer() == lib.Helper() {
+		t.Fail()
+	}
+}
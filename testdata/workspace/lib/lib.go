@@ -0,0 +1,6 @@
+package lib
+
+// Helper is only used by the app module tests.
+func Helper() int { return 1 }
+
+func Other() int { return Helper() }
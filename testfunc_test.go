@@ -0,0 +1,75 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestIsTestFunc(t *testing.T) {
+	const src = `package p
+
+func TestMain(m *testing.M) {}
+func Test(t *testing.T) {}
+func TestFoo(t *testing.T) {}
+func Test_foo(t *testing.T) {}
+func Testfoo() {}
+func Test1(t *testing.T) {}
+func BenchmarkFoo(b *testing.B) {}
+func Benchmarkfoo() {}
+func Example() {}
+func ExampleFoo() {}
+func ExampleT_Method() {}
+func Examplefoo() {}
+func FuzzFoo(f *testing.F) {}
+func Fuzzfoo() {}
+func Helper() {}
+
+type T struct{}
+
+func (T) TestFoo() {}
+`
+	tests := map[string]bool{
+		"TestMain":        true,
+		"Test":            true,
+		"TestFoo":         true,
+		"Test_foo":        true,
+		"Testfoo":         false,
+		"Test1":           true,
+		"BenchmarkFoo":    true,
+		"Benchmarkfoo":    false,
+		"Example":         true,
+		"ExampleFoo":      true,
+		"ExampleT_Method": true,
+		"Examplefoo":      false,
+		"FuzzFoo":         true,
+		"Fuzzfoo":         false,
+		"Helper":          false,
+		"T.TestFoo":       false, // Methods are not tests
+	}
+
+	for _, filename := range []string{"p_test.go", "p.go"} {
+		var l linter
+		l.fset = token.NewFileSet()
+		f, err := parser.ParseFile(l.fset, filename, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			name := decl.Name.Name
+			if decl.Recv != nil {
+				name = "T." + name
+			}
+			want := tests[name] && filename == "p_test.go"
+			have := l.isTestFunc(&symbol{ident: decl.Name, decl: decl, kind: "func"})
+			if have != want {
+				t.Errorf("%s: %s: have %v, want %v", filename, name, have, want)
+			}
+		}
+	}
+}
@@ -1,16 +1,28 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"log"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-toolsmith/pkgload"
+	"github.com/quasilyte/go-unexport/internal/refs"
+	"golang.org/x/tools/cover"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -23,54 +35,601 @@ func main() {
 	}{
 		{"init linter", l.init},
 		{"parse flags", l.parseFlags},
+		{"read ignore file", l.readIgnoreFile},
+		{"read coverage profile", l.readCoverage},
+		{"read feasibility cache", l.readFeasibilityCache},
+		{"read undo log", l.readUndoLog},
 		{"load targets", l.loadTargets},
+		{"normalize receivers", l.normalizeReceivers},
+		{"index references", l.indexReferences},
 		{"collect symbols", l.collectSymbols},
+		{"undo renames", l.undoRenames},
+		{"emit positions", l.emitPositions},
+		{"print API report", l.printAPIReport},
+		{"order symbols", l.orderSymbols},
+		{"print doc preview", l.printDocPreview},
+		{"explain feasibility", l.explainFeasibility},
+		{"print registry report", l.printRegistryReport},
+		{"print impact", l.printImpact},
+		{"write dot graph", l.writeDot},
+		{"check feasibility", l.checkAllFeasible},
 		{"unexport symbols", l.unexportSymbols},
+		{"write edits", l.writeEdits},
+		{"apply edits", l.applyEdits},
+		{"write facade", l.writeFacade},
+		{"run hooks", l.runHooks},
+		{"update API baseline", l.updateAPIBaseline},
+		{"write audit log", l.writeAuditLog},
 		{"print results", l.printResults},
+		{"write summary", l.writeSummary},
+		{"write notify list", l.writeNotifyList},
+		{"check requested", l.checkRequested},
 	}
 
 	for _, step := range steps {
 		if err := step.fn(); err != nil {
+			if err == errDone {
+				break
+			}
+			if err == errCandidates {
+				os.Exit(1)
+			}
 			log.Fatalf("%s: %v", step.name, err)
 		}
 	}
 }
 
+// verbs are the subcommands, see parseFlags for their flags.
+// Running without a subcommand is the same as "run".
+//
+//   - list prints the candidates, like -emit-positions;
+//   - check explains the candidates feasibility, like -explain-feasibility,
+//     and exits with status 1 if any of them can be unexported;
+//   - undo reverts the renames of the last -audit-log run.
+var verbs = []string{"run", "list", "check", "undo"}
+
+// splitVerb returns the args subcommand and the rest of args.
+func splitVerb(args []string) (string, []string) {
+	if len(args) != 0 && slices.Contains(verbs, args[0]) {
+		return args[0], args[1:]
+	}
+	return "run", args
+}
+
+// errDone is returned by a step to finish the run early.
+var errDone = errors.New("done")
+
+// errCandidates is returned by the check verb if some symbols
+// can be unexported, the tool exits with status 1.
+var errCandidates = errors.New("found symbols to unexport")
+
 type linter struct {
+	// verb is the subcommand, "run" by default.
+	verb string
+
 	fset *token.FileSet
 	pkgs []*packages.Package
 
+	// loaded contains all loaded packages, including external tests.
+	loaded []*packages.Package
+	refs   *refs.Index
+
+	// moduleRoot is a directory with go.mod file,
+	// if the tool is running inside a module.
+	moduleRoot string
+
+	// root is a directory reported paths are relative to.
+	// Empty unless -relative-paths is set.
+	root string
+
 	flags struct {
-		targets  []string
-		verbose  bool
-		unexport string
-		skip     string
+		targets    []string
+		verbose    bool
+		unexport   string
+		skip       string
+		edits      string
+		renamer    string
+		examples   string
+		deleteDead bool
+		relative   bool
+
+		nameStyle   string
+		kindStyles  map[string]*string // Per-kind -name-style overrides
+		checkNaming bool
+		fix         bool
+
+		coordinateInternal bool
+
+		manifest      string
+		emitPositions bool
+
+		requireRequested bool
+		cacheDir         string
+		jsonSummary      string
+		apiReport        bool
+		pathMap          pathMapFlag
+
+		concurrency          int
+		concurrencyPerModule int
+
+		docPreview bool
+		coverage   string
+
+		keepLinknamed bool
+		leafOnly      bool
+
+		explainFeasibility bool
+
+		afterEach string
+		afterAll  string
+
+		workspace bool
+
+		preserveAPIFile string
+		auditLog        string
+
+		platforms string
+
+		deprecationShims bool
+
+		onlyIfAllFeasible bool
+
+		registryFuncs string
+
+		groups groupsFlag
+
+		stabilityComment string
+
+		facade     string
+		facadeKeep string
+
+		impact string
+
+		maxReport int
+
+		showEquivalentName bool
+
+		semver             bool
+		allowBreakingMajor bool
+
+		reportSkipped bool
+
+		allowBreakingPrefix string
+
+		feasibilityCache string
+
+		dot    string
+		dotOut string
+
+		normalizeReceivers bool
+
+		allowBreakingTests bool
+
+		within string
+
+		dedupeResults bool
+
+		notifyList string
+
+		subtree string
 	}
 
+	// toUnexported returns an unexported form of the given name.
+	// Use unexportedName to respect the per-kind overrides.
+	toUnexported func(string) string
+
+	// kindStyles are the per-kind toUnexported overrides.
+	kindStyles map[string]func(string) string
+
+	// offsetMapper, if not nil, is applied to the gorename -offset
+	// argument file name. It's useful when gorename runs on
+	// a different machine or inside a container, where the sources
+	// are mounted under another path.
+	offsetMapper func(string) string
+
 	unexport map[string]bool
-	skip     map[string]bool
+	skip     []string // Symbol name patterns
+	// skipFiles are file path patterns, relative to the module root.
+	skipFiles []string
 
-	symbols []*ast.Ident
+	symbols []*symbol
 	success map[string]string
+
+	// shimmed are the renames that keep a deprecated exported shim.
+	shimmed map[string]string
+
+	// edits are collected instead of running gorename if -edits is set.
+	edits jsonTree
+
+	// pending are the in-process renamer edits that are applied
+	// all at once, so every offset matches the loaded sources.
+	pending []textEdit
+
+	// examples maps file name to the example funcs offset ranges.
+	examples map[string][][2]int
+
+	// cycles maps symbols to the other symbol of their rename cycle.
+	cycles map[*symbol]*symbol
+	// renamed contains symbols that were renamed or deleted during this run.
+	renamed map[refs.Key]bool
+	// taken maps scope-qualified new names to the symbols that took them.
+	taken map[string]*symbol
+
+	// requestedFailures are -unexport listed symbols that were not unexported.
+	requestedFailures []string
+
+	// exportedBefore is a number of exported symbols in the processed
+	// packages; exportedRemoved is how many of them were unexported.
+	// Test files are not counted.
+	exportedBefore  int
+	exportedRemoved int
+
+	// excluded maps package path to the names used in its
+	// build-constraint-excluded files. See excludedFiles.
+	excluded map[string]map[string][]string
+
+	// linknames maps go:linkname symbol names to the directive positions.
+	// Lazily initialized by linknamedAt.
+	linknames map[string]string
+
+	// imported is a set of package paths that have importers among
+	// the loaded packages. Lazily initialized by isImported.
+	imported map[string]bool
+
+	// hookFiles are the files touched by the in-process renames
+	// that are passed to the -after-each hook once edits are applied.
+	hookFiles []string
+
+	// changed maps package paths to the number of their
+	// exported symbols that were unexported or deleted.
+	changed map[string]int
+
+	// renames are the successful renames for the -json-summary.
+	renames []jsonRename
+
+	// facadeKeep is a set of -facade-keep symbols, facadeSyms are their
+	// declarations that are re-exported by the -facade file.
+	facadeKeep map[string]bool
+	facadeSyms []*symbol
+
+	// skipped are the exported symbols that are not candidates.
+	// Only collected with -report-skipped.
+	skipped []jsonSkipped
+
+	// notify maps the -notify-list package paths
+	// to the symbols they prevent from being unexported.
+	notify map[string][]string
+
+	// subtreePaths are the -subtree package paths, nil if it's not set.
+	subtreePaths map[string]bool
+
+	// withinPaths are the -within package paths, nil if it's not set.
+	withinPaths map[string]bool
+
+	// testFiles are the other packages test files that
+	// are left broken due to -allow-breaking-tests.
+	testFiles map[string]bool
+
+	// feasibilityKey is the -feasibility-cache key of this run inputs.
+	feasibilityKey string
+
+	// audit are the -audit-log records of this run.
+	audit    []auditRecord
+	auditRun string
+
+	// undo are the -audit-log records that the undo verb reverts.
+	undo []auditRecord
+
+	// platformOf maps the packages loaded for -platforms to their GOOS/GOARCH.
+	platformOf map[*packages.Package]string
+
+	// api maps package path to its exported symbols count per kind.
+	api map[string]map[string]int
+
+	// moduleVersions caches moduleVersion results by module path.
+	moduleVersions map[string]string
+
+	// mu guards the linter state while the symbols are processed
+	// concurrently, see processConcurrently.
+	mu sync.Mutex
+
+	// coverage maps profile file names to their profiles.
+	// Nil unless -coverage is set.
+	coverage map[string]*cover.Profile
+}
+
+type symbol struct {
+	ident *ast.Ident
+	obj   types.Object // Can be nil for symbols like init funcs
+	pkg   *packages.Package
+
+	decl ast.Decl
+	spec ast.Spec // Nil for funcs
+	kind string   // One of the symbolKinds
+
+	group *targetGroup // Nil unless -group is used
+
+	// requiredByInterface is computed by processConcurrently
+	// in advance, see linter.requiredByInterface.
+	requiredByInterface *bool
 }
 
 func (l *linter) parseFlags() error {
-	flag.BoolVar(&l.flags.verbose, "v", false,
+	verb, args := splitVerb(os.Args[1:])
+	l.verb = verb
+
+	// Every flag is registered only for the verbs it's relevant to,
+	// so "list -delete-dead" is rejected. For the other verbs it's
+	// registered in a detached set that just assigns the default value.
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0])+" "+verb, flag.ExitOnError)
+	detached := flag.NewFlagSet(verb, flag.ContinueOnError)
+	on := func(names ...string) *flag.FlagSet {
+		if slices.Contains(names, verb) {
+			return fs
+		}
+		return detached
+	}
+	all := on("run", "list", "check", "undo")
+	candidates := on("run", "list", "check")
+	feasibility := on("run", "check")
+	run := on("run")
+
+	all.BoolVar(&l.flags.verbose, "v", false,
 		`print more information than usually`)
-	flag.StringVar(&l.flags.unexport, "unexport", "",
+	candidates.StringVar(&l.flags.unexport, "unexport", "",
 		`comma-separated list of symbols to unexport; if empty, reads as 'all'`)
-	flag.StringVar(&l.flags.skip, "skip", "",
-		`comma-separated list of symbols not to unexport`)
+	candidates.StringVar(&l.flags.skip, "skip", "",
+		`comma-separated list of symbols not to unexport; glob patterns like Test* are permitted`)
+	run.StringVar(&l.flags.edits, "edits", "",
+		`write renames as go/analysis JSON suggested fixes to the specified file instead of applying them`)
+	run.StringVar(&l.flags.renamer, "renamer", "gorename",
+		`renaming backend: gorename or inprocess`)
+	feasibility.StringVar(&l.flags.examples, "examples", "block",
+		`whether references from Example funcs block the rename: block or ignore; ignore requires -renamer=inprocess`)
+	run.BoolVar(&l.flags.deleteDead, "delete-dead", false,
+		`delete unused symbols instead of unexporting them; requires -renamer=inprocess`)
+	all.BoolVar(&l.flags.relative, "relative-paths", false,
+		`print file paths relative to the main module root or the working directory`)
+	candidates.StringVar(&l.flags.nameStyle, "name-style", "lower-first",
+		`unexported names style: lower-first (URLParser -> uRLParser) or acronym (URLParser -> urlParser)`)
+	l.flags.kindStyles = make(map[string]*string)
+	for _, kind := range symbolKinds {
+		l.flags.kindStyles[kind] = candidates.String("name-style-"+kind, "",
+			fmt.Sprintf(`unexported %s names style; overrides -name-style`, kind))
+	}
+	run.BoolVar(&l.flags.checkNaming, "check-naming", false,
+		`also report unexported symbols that don't follow the acronym name style`)
+	run.BoolVar(&l.flags.fix, "fix", false,
+		`rename symbols reported by -check-naming`)
+	candidates.StringVar(&l.flags.manifest, "manifest", "",
+		`load packages described by the JSON manifest file instead of the command line targets`)
+	run.BoolVar(&l.flags.emitPositions, "emit-positions", false,
+		`only print file:line:col:endcol:name:kind of every candidate, without renaming anything`)
+	run.BoolVar(&l.flags.requireRequested, "require-requested", false,
+		`fail if any symbol explicitly listed in -unexport can't be unexported`)
+	feasibility.StringVar(&l.flags.cacheDir, "cache-dir", "",
+		`directory to cache the references analysis results between runs`)
+	run.StringVar(&l.flags.jsonSummary, "json-summary", "",
+		`write the run summary as JSON to the specified file`)
+	run.BoolVar(&l.flags.apiReport, "api-report", false,
+		`only print the number of exported symbols of every kind per package, without renaming anything`)
+	run.Var(&l.flags.pathMap, "path-map",
+		`from=to path prefix replacement for the files passed to gorename; can be repeated`)
+	run.IntVar(&l.flags.concurrency, "concurrency", 1,
+		`max number of packages processed concurrently; requires -renamer=inprocess`)
+	run.IntVar(&l.flags.concurrencyPerModule, "max-concurrency-per-module", min(runtime.GOMAXPROCS(0), 8),
+		`max number of packages of the same module processed concurrently; see -concurrency`)
+	run.BoolVar(&l.flags.docPreview, "doc-preview", false,
+		`only print the packages documentation as it would look after unexporting, without renaming anything`)
+	candidates.StringVar(&l.flags.coverage, "coverage", "",
+		`coverage profile file; if set, only funcs and methods that were never executed are unexported`)
+	feasibility.BoolVar(&l.flags.keepLinknamed, "keep-linknamed", true,
+		`whether to skip symbols that are mentioned in go:linkname directives`)
+	candidates.BoolVar(&l.flags.leafOnly, "leaf-only", false,
+		`only process packages that are not imported by any other loaded package`)
+	run.BoolVar(&l.flags.explainFeasibility, "explain-feasibility", false,
+		`print all reasons that prevent each symbol from being unexported and exit`)
+	run.StringVar(&l.flags.afterEach, "after-each", "",
+		`command to run after each successful rename, {file} is replaced by every touched file path`)
+	run.StringVar(&l.flags.afterAll, "after-all", "",
+		`command to run once after all renames`)
+	feasibility.BoolVar(&l.flags.workspace, "workspace", false,
+		`whether to also index references from all go.work modules, including their tests`)
+	run.StringVar(&l.flags.preserveAPIFile, "preserve-api-file", "",
+		`apidiff baseline file to regenerate for the changed packages, {pkg} is replaced by the package path`)
+	on("run", "undo").StringVar(&l.flags.auditLog, "audit-log", "",
+		`file to append a JSON line to for every applied rename`)
+	feasibility.StringVar(&l.flags.platforms, "platforms", "",
+		`comma-separated GOOS/GOARCH list to also look for the references on`)
+	feasibility.BoolVar(&l.flags.deprecationShims, "deprecation-shims", false,
+		`whether to keep deprecated exported wrappers for the funcs that are used by other packages`)
+	run.BoolVar(&l.flags.onlyIfAllFeasible, "only-if-all-feasible", false,
+		`whether to abort without any changes if some symbol can't be unexported`)
+	run.StringVar(&l.flags.registryFuncs, "registry-funcs", "",
+		`comma-separated registry func names; if set, report symbols that are only referenced in their calls and exit`)
+	candidates.Var(&l.flags.groups, "group",
+		`'name=x;pkgs=a,b;unexport=A,B;skip=C' target group with its own settings; can be repeated`)
+	candidates.StringVar(&l.flags.stabilityComment, "stability-comment", "",
+		`directive like "go:api stable" that marks symbols or whole files that must stay exported`)
+	run.StringVar(&l.flags.facade, "facade", "",
+		`file to generate with the re-exports of the -facade-keep symbols`)
+	run.StringVar(&l.flags.facadeKeep, "facade-keep", "",
+		`comma-separated symbols that are kept exported and re-exported by the -facade file`)
+	run.StringVar(&l.flags.impact, "impact", "",
+		`only print the packages that reference the specified symbol (or Type.Method), without renaming anything`)
+	run.IntVar(&l.flags.maxReport, "max-report", 0,
+		`max number of entries printed per results listing; 0 means no limit`)
+	on("run", "list").BoolVar(&l.flags.showEquivalentName, "show-equivalent-name", false,
+		`with -emit-positions, also print the unexported name of every candidate`)
+	feasibility.BoolVar(&l.flags.semver, "semver", false,
+		`whether to refuse unexporting the API of importable packages of v1+ modules, see -allow-breaking-major`)
+	feasibility.BoolVar(&l.flags.allowBreakingMajor, "allow-breaking-major", false,
+		`with -semver, allow the API changes that need a new module major version`)
+	run.BoolVar(&l.flags.reportSkipped, "report-skipped", false,
+		`whether to report the skipped exported symbols with the reasons; printed with -v and included in -json-summary`)
+	feasibility.StringVar(&l.flags.allowBreakingPrefix, "allow-breaking-prefix", "",
+		`comma-separated import path prefixes of the packages which references don't block renames; the references are left broken and the renames are reported as breaking; requires -renamer=inprocess`)
+	feasibility.StringVar(&l.flags.feasibilityCache, "feasibility-cache", "",
+		`file to cache the -explain-feasibility report in; reused while the inputs are unchanged`)
+	run.StringVar(&l.flags.dot, "dot", "",
+		`only write a graphviz graph of the packages that reference the specified symbol (or Type.Method), "all" for every candidate`)
+	run.StringVar(&l.flags.dotOut, "dot-out", "",
+		`file to write the -dot graph to; stdout by default`)
+	run.BoolVar(&l.flags.normalizeReceivers, "normalize-receivers", false,
+		`only rename method receivers to the lower-case initials of their type names, without unexporting anything`)
+	feasibility.BoolVar(&l.flags.allowBreakingTests, "allow-breaking-tests", false,
+		`whether references from the other packages test files don't block renames; the references are left broken and the renames are reported as breaking; requires -renamer=inprocess`)
+	candidates.StringVar(&l.flags.within, "within", "",
+		`comma-separated package patterns to unexport symbols in; the other targets are only checked for references; the references from the other -within packages don't block renames, but they're left broken and the renames are reported as breaking; requires -renamer=inprocess`)
+	run.BoolVar(&l.flags.dedupeResults, "dedupe-results", false,
+		`whether to report every symbol once, keyed by its package, kind, receiver type and name instead of its position`)
+	run.StringVar(&l.flags.notifyList, "notify-list", "",
+		`file to write a task list of the packages that prevent symbols from being unexported to`)
+	candidates.StringVar(&l.flags.subtree, "subtree", "",
+		`directory to unexport symbols in; only its packages and their dependents from the same module are loaded, instead of the targets`)
+	feasibility.BoolVar(&l.flags.coordinateInternal, "coordinate-internal", false,
+		`rename symbols of internal packages even if they're used by other packages of the tree; their references are left broken and the renames are reported as breaking; requires -renamer=inprocess`)
 
-	flag.Parse()
+	fs.Parse(args)
 
-	l.flags.targets = flag.Args()
+	l.flags.targets = fs.Args()
+	switch verb {
+	case "list":
+		l.flags.emitPositions = true
+	case "check":
+		l.flags.explainFeasibility = true
+	case "undo":
+		if l.flags.auditLog == "" {
+			return fmt.Errorf("undo requires -audit-log")
+		}
+		l.flags.renamer = "inprocess"
+	}
+	if l.flags.manifest != "" && len(l.flags.targets) != 0 {
+		return fmt.Errorf("-manifest can't be combined with targets")
+	}
+	if len(l.flags.groups) != 0 {
+		if l.flags.manifest != "" {
+			return fmt.Errorf("-group can't be used with -manifest")
+		}
+		for _, g := range l.flags.groups {
+			l.flags.targets = append(l.flags.targets, g.patterns...)
+		}
+	}
 
 	for _, sym := range strings.Split(l.flags.unexport, ",") {
 		l.unexport[sym] = true
 	}
-	for _, sym := range strings.Split(l.flags.skip, ",") {
-		l.skip[sym] = true
+	if l.flags.facadeKeep != "" {
+		if l.flags.facade == "" {
+			return fmt.Errorf("-facade-keep requires -facade")
+		}
+		l.facadeKeep = make(map[string]bool)
+		for _, name := range splitList(l.flags.facadeKeep) {
+			l.facadeKeep[name] = true
+		}
+	}
+	for _, pattern := range strings.Split(l.flags.skip, ",") {
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("-skip: %q: %v", pattern, err)
+		}
+		l.skip = append(l.skip, pattern)
+	}
+
+	switch l.flags.renamer {
+	case "gorename", "inprocess":
+	default:
+		return fmt.Errorf("unknown renamer %q", l.flags.renamer)
+	}
+	// Every gorename run loads the whole workspace,
+	// it can't run while another one rewrites the files.
+	if l.flags.concurrency > 1 && l.flags.renamer == "gorename" && l.flags.edits == "" {
+		return fmt.Errorf("-concurrency requires -renamer=inprocess")
+	}
+	if l.flags.concurrencyPerModule < 1 {
+		return fmt.Errorf("-max-concurrency-per-module should be positive")
+	}
+
+	if l.flags.subtree != "" && (l.flags.manifest != "" || len(l.flags.targets) != 0 || l.flags.workspace) {
+		return fmt.Errorf("-subtree can't be combined with targets, -manifest or -workspace")
+	}
+	if l.flags.within != "" && l.flags.manifest != "" {
+		return fmt.Errorf("-within can't be used with -manifest")
+	}
+	if l.flags.normalizeReceivers && l.flags.edits != "" {
+		return fmt.Errorf("-normalize-receivers can't be used with -edits")
+	}
+	if l.flags.dotOut != "" && l.flags.dot == "" {
+		return fmt.Errorf("-dot-out requires -dot")
+	}
+	if l.flags.feasibilityCache != "" && !l.flags.explainFeasibility {
+		return fmt.Errorf("-feasibility-cache requires -explain-feasibility")
+	}
+	if l.flags.feasibilityCache != "" && l.flags.workspace {
+		return fmt.Errorf("-feasibility-cache can't be used with -workspace")
+	}
+	if l.flags.showEquivalentName && !l.flags.emitPositions {
+		return fmt.Errorf("-show-equivalent-name requires -emit-positions")
+	}
+
+	if l.flags.workspace && l.flags.manifest != "" {
+		return fmt.Errorf("-workspace can't be used with -manifest")
+	}
+
+	if l.flags.platforms != "" {
+		if l.flags.manifest != "" {
+			return fmt.Errorf("-platforms can't be used with -manifest")
+		}
+		if err := parsePlatforms(l.flags.platforms); err != nil {
+			return err
+		}
+	}
+
+	if len(l.flags.pathMap) != 0 {
+		l.offsetMapper = l.flags.pathMap.apply
+	}
+
+	l.toUnexported = nameStyles[l.flags.nameStyle]
+	if l.toUnexported == nil {
+		return fmt.Errorf("unknown name style %q", l.flags.nameStyle)
+	}
+	l.kindStyles = make(map[string]func(string) string)
+	for kind, style := range l.flags.kindStyles {
+		if *style == "" {
+			continue
+		}
+		l.kindStyles[kind] = nameStyles[*style]
+		if l.kindStyles[kind] == nil {
+			return fmt.Errorf("-name-style-%s: unknown name style %q", kind, *style)
+		}
+	}
+	switch l.flags.examples {
+	case "block", "ignore":
+	default:
+		return fmt.Errorf("unknown examples mode %q", l.flags.examples)
+	}
+
+	// These options need the edits that gorename can't do.
+	inprocessOnly := []struct {
+		name string
+		set  bool
+	}{
+		{"-examples=ignore", l.flags.examples == "ignore"},
+		{"-delete-dead", l.flags.deleteDead},
+		{"-coordinate-internal", l.flags.coordinateInternal},
+		{"-deprecation-shims", l.flags.deprecationShims},
+		{"-allow-breaking-prefix", l.flags.allowBreakingPrefix != ""},
+		{"-allow-breaking-tests", l.flags.allowBreakingTests},
+		{"-within", l.flags.within != ""},
+	}
+	if verb == "run" && l.flags.renamer != "inprocess" && l.flags.edits == "" {
+		for _, opt := range inprocessOnly {
+			if opt.set {
+				return fmt.Errorf("%s can't be used with %s renamer", opt.name, l.flags.renamer)
+			}
+		}
 	}
 
 	return nil
@@ -78,15 +637,64 @@ func (l *linter) parseFlags() error {
 
 func (l *linter) init() error {
 	l.unexport = make(map[string]bool)
-	l.skip = make(map[string]bool)
 	l.success = make(map[string]string)
+	l.shimmed = make(map[string]string)
+	l.edits = make(jsonTree)
+	l.examples = make(map[string][][2]int)
+	l.cycles = make(map[*symbol]*symbol)
+	l.renamed = make(map[refs.Key]bool)
+	l.taken = make(map[string]*symbol)
+	l.excluded = make(map[string]map[string][]string)
+	l.api = make(map[string]map[string]int)
+	l.changed = make(map[string]int)
 	return nil
 }
 
 func (l *linter) loadTargets() error {
 	l.fset = token.NewFileSet()
+
+	var err error
+	if l.flags.manifest != "" {
+		l.loaded, err = loadManifest(l.fset, l.flags.manifest)
+		l.pkgs = l.loaded
+	} else {
+		err = l.loadPackages()
+	}
+	if err != nil {
+		return err
+	}
+
+	if l.flags.relative {
+		l.root, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+		for _, pkg := range l.loaded {
+			if pkg.Module != nil && pkg.Module.Main {
+				l.root = pkg.Module.Dir
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *linter) loadPackages() error {
+	if err := l.resolveGroups(); err != nil {
+		return err
+	}
+	if err := l.resolveWithin(); err != nil {
+		return err
+	}
+	if l.flags.subtree != "" {
+		if err := l.resolveSubtree(); err != nil {
+			return err
+		}
+	}
+
 	cfg := &packages.Config{
-		Mode:  packages.LoadSyntax,
+		Mode:  packages.LoadSyntax | packages.NeedModule,
 		Tests: true,
 		Fset:  l.fset,
 	}
@@ -95,6 +703,7 @@ func (l *linter) loadTargets() error {
 	if err != nil {
 		return err
 	}
+	l.loaded = pkgs
 
 	pkgload.VisitUnits(pkgs, func(u *pkgload.Unit) {
 		if u.Test != nil {
@@ -103,24 +712,102 @@ func (l *linter) loadTargets() error {
 			l.pkgs = append(l.pkgs, u.Base)
 		}
 	})
+	// VisitUnits skips the packages without a name, like the ones
+	// with no Go files. They are kept for the reportNoSyntax.
+	for _, pkg := range pkgs {
+		if pkg.Name == "" {
+			l.pkgs = append(l.pkgs, pkg)
+		}
+	}
+	l.pkgs = dedupePackages(l.pkgs)
 
+	if l.flags.platforms != "" {
+		if err := l.loadPlatforms(); err != nil {
+			return err
+		}
+	}
+	if l.flags.workspace {
+		return l.loadWorkspace()
+	}
+	return nil
+}
+
+// dedupePackages removes the packages with the same path, which
+// can be there due to overlapping patterns, like ./foo and ./...
+// The test variant of a package is preferred as it has more files.
+func dedupePackages(pkgs []*packages.Package) []*packages.Package {
+	index := make(map[string]int, len(pkgs))
+	list := pkgs[:0]
+	for _, pkg := range pkgs {
+		i, ok := index[pkg.PkgPath]
+		if !ok {
+			index[pkg.PkgPath] = len(list)
+			list = append(list, pkg)
+			continue
+		}
+		if len(pkg.Syntax) > len(list[i].Syntax) {
+			list[i] = pkg
+		}
+	}
+	return list
+}
+
+func (l *linter) indexReferences() error {
+	if l.flags.emitPositions || l.flags.apiReport {
+		return nil // Not needed, saves time
+	}
+	if l.flags.cacheDir != "" {
+		idx, err := refs.NewCachedIndex(l.fset, l.loaded, l.flags.cacheDir)
+		if err != nil {
+			return err
+		}
+		l.refs = idx
+	} else {
+		l.refs = refs.NewIndex(l.fset, l.loaded)
+	}
+	l.refs.FormatPos = func(posn token.Position) string {
+		posn.Filename = l.relPath(posn.Filename)
+		return posn.String()
+	}
 	return nil
 }
 
 func (l *linter) collectSymbols() error {
+	for _, pkg := range l.loaded {
+		for _, f := range pkg.Syntax {
+			l.collectExamples(f)
+		}
+	}
+
 	for _, pkg := range l.pkgs {
+		if len(pkg.Syntax) == 0 && l.flags.verbose {
+			l.reportNoSyntax(pkg)
+		}
 		for _, f := range pkg.Syntax {
 			if l.fset.Position(f.Pos()).Filename == "" {
 				continue
 			}
-			l.collectFileSymbols(f)
+			l.collectFileSymbols(pkg, f)
 		}
 	}
 
 	return nil
 }
 
-func (l *linter) collectFileSymbols(f *ast.File) {
+// reportNoSyntax explains why nothing is collected from pkg.
+// This can happen due to load errors, binary-only packages
+// or packages.Load mode that is not enough to get the syntax.
+func (l *linter) reportNoSyntax(pkg *packages.Package) {
+	fmt.Printf("%s: no syntax files loaded, skipping\n", pkg.ID)
+	for _, err := range pkg.Errors {
+		fmt.Printf("\t%v\n", err)
+	}
+	if len(pkg.Errors) == 0 && len(pkg.GoFiles) == 0 {
+		fmt.Printf("\tpackage has no Go files\n")
+	}
+}
+
+func (l *linter) collectFileSymbols(pkg *packages.Package, f *ast.File) {
 	for _, decl := range f.Decls {
 		switch decl := decl.(type) {
 		case *ast.GenDecl:
@@ -128,67 +815,533 @@ func (l *linter) collectFileSymbols(f *ast.File) {
 				switch spec := spec.(type) {
 				case *ast.ValueSpec:
 					for _, id := range spec.Names {
-						l.collectSym(id)
+						l.collectSym(&symbol{ident: id, pkg: pkg, decl: decl, spec: spec, kind: decl.Tok.String()})
 					}
 				case *ast.TypeSpec:
-					l.collectSym(spec.Name)
+					l.collectSym(&symbol{ident: spec.Name, pkg: pkg, decl: decl, spec: spec, kind: "type"})
 				}
 			}
 		case *ast.FuncDecl:
-			l.collectSym(decl.Name)
+			kind := "func"
+			if decl.Recv != nil {
+				kind = "method"
+			}
+			l.collectSym(&symbol{ident: decl.Name, pkg: pkg, decl: decl, kind: kind})
 		}
 	}
 
 }
 
-func (l *linter) collectSym(sym *ast.Ident) {
-	if l.unexport != nil || l.unexport[sym.Name] {
-		if !l.skip[sym.Name] {
-			l.symbols = append(l.symbols, sym)
+func (l *linter) collectExamples(f *ast.File) {
+	filename := l.fset.Position(f.Pos()).Filename
+	if !strings.HasSuffix(filename, "_test.go") || len(l.examples[filename]) != 0 {
+		return
+	}
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || decl.Recv != nil || !strings.HasPrefix(decl.Name.Name, "Example") {
+			continue
 		}
+		start := l.fset.Position(decl.Pos()).Offset
+		end := l.fset.Position(decl.End()).Offset
+		l.examples[filename] = append(l.examples[filename], [2]int{start, end})
 	}
 }
 
-func (l *linter) unexportSymbols() error {
+func (l *linter) collectSym(sym *symbol) {
+	if sym.ident.IsExported() && !l.inTestFile(sym) {
+		l.exportedBefore++
+		l.countAPI(sym)
+	}
+	if l.isTestFunc(sym) {
+		l.addSkipped(sym, "go test function")
+		return // Must stay exported for go test to find it
+	}
+	if l.facadeKeep[sym.ident.Name] && sym.ident.IsExported() {
+		if !l.inTestFile(sym) {
+			sym.obj = sym.pkg.TypesInfo.Defs[sym.ident]
+			l.facadeSyms = append(l.facadeSyms, sym)
+		}
+		l.addSkipped(sym, "kept by -facade-keep")
+		return
+	}
+	if reason := l.skipReason(sym); reason != "" {
+		l.addSkipped(sym, reason)
+		return
+	}
+	sym.obj = sym.pkg.TypesInfo.Defs[sym.ident]
+	l.symbols = append(l.symbols, sym)
+}
+
+// skipReason returns a reason why sym is not a candidate,
+// or an empty string if it is.
+func (l *linter) skipReason(sym *symbol) string {
+	switch {
+	case l.flags.unexport != "" && sym.ident.IsExported() && !l.unexport[sym.ident.Name]:
+		// Unexported symbols are kept for -check-naming.
+		return "not listed in -unexport"
+	case !l.inSubtree(sym.pkg.PkgPath):
+		return "outside of -subtree"
+	case !l.isWithin(sym.pkg.PkgPath):
+		return "outside of -within packages"
+	case l.flags.leafOnly && l.isImported(sym.pkg):
+		return "package is imported by other packages (-leaf-only)"
+	case !l.groupAllows(sym):
+		return "excluded by -group settings"
+	case l.isSkipped(sym):
+		return "matches -skip or ignore file pattern"
+	case l.isCovered(sym):
+		return "not a never executed func (-coverage)"
+	case l.isStable(sym):
+		return "marked by -stability-comment"
+	}
+	return ""
+}
+
+// addSkipped records exported sym that is skipped for -report-skipped.
+func (l *linter) addSkipped(sym *symbol, reason string) {
+	if !l.flags.reportSkipped || !sym.ident.IsExported() {
+		return
+	}
+	posn := l.position(sym.ident.Pos())
+	l.skipped = append(l.skipped, jsonSkipped{
+		Filename: posn.Filename,
+		Line:     posn.Line,
+		Column:   posn.Column,
+		Offset:   posn.Offset,
+		Name:     sym.ident.Name,
+		Reason:   reason,
+	})
+}
+
+func (l *linter) emitPositions() error {
+	if !l.flags.emitPositions {
+		return nil
+	}
+
 	for _, sym := range l.symbols {
-		if ast.IsExported(sym.Name) {
-			fmt.Printf("trying to unexport %s... ", sym.Name)
-			status := l.tryUnexport(sym.Pos(), sym.Name)
-			fmt.Println("(" + status + ")")
+		if !ast.IsExported(sym.ident.Name) {
+			continue
+		}
+		posn := l.position(sym.ident.Pos())
+		endcol := posn.Column + len(sym.ident.Name)
+		fmt.Printf("%s:%d:%d:%d:%s:%s",
+			posn.Filename, posn.Line, posn.Column, endcol, sym.ident.Name, sym.kind)
+		if l.flags.showEquivalentName {
+			fmt.Printf(":%s", l.unexportedName(sym))
 		}
+		fmt.Println()
+	}
+
+	return errDone
+}
+
+func (l *linter) unexportSymbols() error {
+	if l.flags.concurrency > 1 {
+		l.processConcurrently()
+		return nil
 	}
 
+	for _, sym := range l.symbols {
+		l.processSymbol(sym)
+	}
 	return nil
 }
 
-func (l *linter) tryUnexport(pos token.Pos, exported string) string {
-	posn := l.fset.Position(pos)
-	offset := fmt.Sprintf("%s:#%d", posn.Filename, posn.Offset)
-	unexported := toLowerFirst(exported)
-	out, err := exec.Command("gorename", "-offset", offset, "-to", unexported).CombinedOutput()
-	key := fmt.Sprintf("%s/%s", posn, exported)
+func (l *linter) processSymbol(sym *symbol) {
+	switch {
+	case ast.IsExported(sym.ident.Name):
+		status := l.tryUnexport(sym)
+		if sym.group != nil {
+			fmt.Printf("[%s] ", sym.group.name)
+		}
+		fmt.Printf("trying to unexport %s... (%s)\n", sym.ident.Name, status)
+		removed := status == "success" || status == "deleted" || strings.HasPrefix(status, "breaking")
+		if removed && !l.inTestFile(sym) {
+			l.exportedRemoved++
+		}
+		if strings.HasPrefix(status, "impossible") {
+			l.addNotify(sym)
+		}
+		if strings.HasPrefix(status, "impossible") && l.isRequested(sym) {
+			l.requestedFailures = append(l.requestedFailures,
+				fmt.Sprintf("%s: %s", l.position(sym.ident.Pos()), sym.ident.Name))
+		}
+	case l.flags.checkNaming:
+		l.checkNaming(sym)
+	}
+}
 
+func (l *linter) tryUnexport(sym *symbol) string {
+	if other := l.cycles[sym]; other != nil {
+		return fmt.Sprintf("impossible: rename cycle with %s", other.ident.Name)
+	}
+	if reason := l.semverBlock(sym); reason != "" {
+		return "impossible: " + reason
+	}
+	if l.flags.deleteDead && l.deleteDead(sym) {
+		l.markRenamed(sym, "")
+		l.recordSuccess(sym, "")
+		l.afterRename(l.renamedFiles(sym))
+		return "deleted"
+	}
+	newName := l.unexportedName(sym)
+	if newName == sym.ident.Name {
+		// The name style has nothing to change, gorename
+		// would reject the rename. It's neither a success nor a failure.
+		return "no-op: already minimal"
+	}
+	return l.tryRename(sym, newName)
+}
+
+// checkNaming reports unexported sym if its name doesn't
+// follow the acronym naming style. With -fix, sym is renamed.
+func (l *linter) checkNaming(sym *symbol) {
+	name := sym.ident.Name
+	want := toLowerAcronym(toUpperFirst(name))
+	if want == name {
+		return
+	}
+	if !l.flags.fix {
+		fmt.Printf("%s: %s should be %s\n", l.position(sym.ident.Pos()), name, want)
+		return
+	}
+	var status string
+	if other := l.cycles[sym]; other != nil {
+		status = fmt.Sprintf("impossible: rename cycle with %s", other.ident.Name)
+	} else {
+		status = l.tryRename(sym, want)
+	}
+	fmt.Printf("%s: %s should be %s\ntrying to rename %s... (%s)\n",
+		l.position(sym.ident.Pos()), name, want, name, status)
+}
+
+func (l *linter) tryRename(sym *symbol, newName string) string {
+	if files := l.excludedFiles(sym); len(files) != 0 {
+		return "impossible: used in build-constraint-excluded files: " + strings.Join(files, ", ")
+	}
+	if posn := l.linknamedAt(sym); posn != "" {
+		return "impossible: mentioned in go:linkname at " + posn
+	}
+
+	if l.flags.edits != "" || l.flags.renamer == "inprocess" {
+		broken := l.brokenRefs(sym)
+		if err := l.renameInProcess(sym, newName); err != nil {
+			return "impossible: " + err.Error()
+		}
+		if len(broken) != 0 {
+			l.recordBreaking(sym, newName, broken)
+			l.afterRename(l.renamedFiles(sym))
+			return "breaking: " + l.describeBroken(broken)
+		}
+		if l.needsShim(sym) {
+			l.recordShimmed(sym, newName)
+			l.afterRename(l.renamedFiles(sym))
+			return "shimmed" // Still exported via the shim
+		}
+		l.recordSuccess(sym, newName)
+		l.afterRename(l.renamedFiles(sym))
+		return "success"
+	}
+
+	// gorename only sees the current platform references.
+	if sym.obj != nil && l.platformOf != nil {
+		if platforms := l.blockingPlatforms(sym); len(platforms) != 0 {
+			return "impossible: would break package clients on " + strings.Join(platforms, ", ")
+		}
+	}
+
+	if err := l.checkSource(sym); err != nil {
+		return "impossible: " + err.Error()
+	}
+	offset, err := l.gorenameOffset(sym.ident.Pos())
+	if err != nil {
+		return "impossible: " + err.Error()
+	}
+	files := l.renamedFiles(sym)
+	out, err := exec.Command("gorename", "-offset", offset, "-to", newName).CombinedOutput()
 	if err != nil {
 		return "impossible: " + prettyError(string(out))
 	}
-	l.success[key] = fmt.Sprintf("%s -> %s", exported, unexported)
+	l.markRenamed(sym, newName)
+	l.recordSuccess(sym, newName)
+	l.afterRename(files)
 	return "success"
 }
 
-func (l *linter) printResults() error {
-	if !l.flags.verbose {
+// checkSource verifies that sym identifier is still located at the
+// loaded position. If the file was changed after it was loaded,
+// the offset passed to gorename could point to another identifier.
+func (l *linter) checkSource(sym *symbol) error {
+	posn := l.fset.Position(sym.ident.Pos())
+	src, err := os.ReadFile(posn.Filename)
+	if err != nil {
+		return err
+	}
+	name := sym.ident.Name
+	end := posn.Offset + len(name)
+	if end > len(src) || string(src[posn.Offset:end]) != name || (end < len(src) && isIdentByte(src[end])) {
+		return fmt.Errorf("source changed: %s is no longer at %s", name, l.position(sym.ident.Pos()))
+	}
+	return nil
+}
+
+// isIdentByte reports whether b can be a part of an identifier.
+// Non-ASCII bytes are treated as identifier parts to be conservative.
+func isIdentByte(b byte) bool {
+	return b == '_' || b >= 0x80 ||
+		('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// gorenameOffset returns the gorename -offset argument for pos.
+//
+// gorename splits the argument by ":#", so Windows drive letters
+// are fine, but a file path that contains ":#" can't be passed.
+func (l *linter) gorenameOffset(pos token.Pos) (string, error) {
+	posn := l.fset.Position(pos)
+	filename := filepath.Clean(posn.Filename)
+	if l.offsetMapper != nil {
+		filename = l.offsetMapper(filename)
+	}
+	if strings.Contains(filename, ":#") {
+		return "", fmt.Errorf("file path %q contains \":#\"", filename)
+	}
+	return fmt.Sprintf("%s:#%d", filename, posn.Offset), nil
+}
+
+// recordSuccess records sym rename to newName.
+// An empty newName means that sym was deleted.
+// It returns the -json-summary entry of the rename,
+// or nil if it's already recorded.
+func (l *linter) recordSuccess(sym *symbol, newName string) *jsonRename {
+	oldName := sym.ident.Name
+	key := l.resultKey(sym)
+	if _, ok := l.success[key]; ok && l.flags.dedupeResults {
+		return nil // Already recorded for another package variant
+	}
+	if newName == "" {
+		l.success[key] = fmt.Sprintf("%s -> <deleted>", oldName)
+	} else {
+		l.success[key] = fmt.Sprintf("%s -> %s", oldName, newName)
+	}
+	if sym.ident.IsExported() && !l.inTestFile(sym) {
+		l.changed[sym.pkg.PkgPath]++
+	}
+	l.addAuditRecord(sym, newName)
+	return l.addRename(sym, newName)
+}
+
+// recordBreaking records a rename that leaves the broken references
+// in the other packages. The symbol is unexported all the same, so
+// it's counted like a success, but the rename is marked as breaking:
+// the references need a manual migration.
+func (l *linter) recordBreaking(sym *symbol, newName string, broken []refs.Ref) {
+	r := l.recordSuccess(sym, newName)
+	if r == nil {
+		return
+	}
+	l.success[l.resultKey(sym)] += " (breaking)"
+	r.Breaking = true
+	for _, ref := range broken {
+		r.BrokenRefs = append(r.BrokenRefs, l.position(ref.Ident.Pos()).String())
+	}
+}
+
+// recordShimmed records sym rename to newName that keeps a deprecated
+// exported shim in its place. The package API stays the same,
+// so it's not counted as a removal.
+func (l *linter) recordShimmed(sym *symbol, newName string) {
+	key := l.resultKey(sym)
+	if _, ok := l.shimmed[key]; ok && l.flags.dedupeResults {
+		return // Already recorded for another package variant
+	}
+	l.shimmed[key] = fmt.Sprintf("%s -> %s", sym.ident.Name, newName)
+	l.addAuditRecord(sym, newName)
+	l.addRename(sym, newName).Shimmed = true
+}
+
+// addRename adds sym rename to the -json-summary renames.
+func (l *linter) addRename(sym *symbol, newName string) *jsonRename {
+	posn := l.position(sym.ident.Pos())
+	l.renames = append(l.renames, jsonRename{
+		Filename: posn.Filename,
+		Line:     posn.Line,
+		Column:   posn.Column,
+		Offset:   posn.Offset,
+		Old:      sym.ident.Name,
+		New:      newName,
+		Deleted:  newName == "",
+	})
+	r := &l.renames[len(l.renames)-1]
+	if sym.group != nil {
+		r.Group = sym.group.name
+	}
+	return r
+}
+
+// resultKey returns sym key in the results report.
+// With -dedupe-results, it's a canonical symbol key that doesn't
+// depend on the package variant, like "example.com/pkg method T.Foo".
+func (l *linter) resultKey(sym *symbol) string {
+	if !l.flags.dedupeResults {
+		return fmt.Sprintf("%s/%s", l.position(sym.ident.Pos()), sym.ident.Name)
+	}
+	name := sym.ident.Name
+	if sym.obj != nil {
+		name = impactName(sym)
+	}
+	return fmt.Sprintf("%s %s %s", sym.pkg.PkgPath, sym.kind, name)
+}
+
+// position returns pos position with a file name
+// that is relative to the l.root, if it's set.
+func (l *linter) position(pos token.Pos) token.Position {
+	posn := l.fset.Position(pos)
+	posn.Filename = l.relPath(posn.Filename)
+	return posn
+}
+
+func (l *linter) relPath(filename string) string {
+	if l.root == "" {
+		return filename
+	}
+	rel, err := filepath.Rel(l.root, filename)
+	if err != nil {
+		return filename
+	}
+	return rel
+}
+
+// isRequested reports whether sym is explicitly listed in -unexport.
+func (l *linter) isRequested(sym *symbol) bool {
+	if sym.group != nil && sym.group.unexport != nil {
+		return sym.group.unexport[sym.ident.Name]
+	}
+	return l.flags.unexport != "" && l.unexport[sym.ident.Name]
+}
+
+func (l *linter) checkRequested() error {
+	if !l.flags.requireRequested || len(l.requestedFailures) == 0 {
 		return nil
 	}
+	return fmt.Errorf("can't unexport requested symbols:\n\t%s",
+		strings.Join(l.limitReport(l.requestedFailures), "\n\t"))
+}
 
-	if len(l.success) != 0 {
-		fmt.Println("unexported:")
+// limitReport truncates lines to -max-report entries,
+// adding a footer with the number of omitted entries.
+func (l *linter) limitReport(lines []string) []string {
+	limit := l.flags.maxReport
+	if limit <= 0 || len(lines) <= limit {
+		return lines
+	}
+	return append(lines[:limit:limit], fmt.Sprintf("(... and %d more)", len(lines)-limit))
+}
+
+func (l *linter) printResults() error {
+	if l.flags.verbose && len(l.success) != 0 {
+		var lines []string
 		for key, renamed := range l.success {
-			fmt.Printf("\t%s: %s\n", key, renamed)
+			lines = append(lines, fmt.Sprintf("%s: %s", key, renamed))
+		}
+		sort.Strings(lines)
+		fmt.Println("unexported:")
+		for _, line := range l.limitReport(lines) {
+			fmt.Printf("\t%s\n", line)
+		}
+
+		lines = lines[:0]
+		for _, p := range l.changedPackages() {
+			lines = append(lines, fmt.Sprintf("%s: %d removed", p.Path, p.Removed))
+		}
+		fmt.Println("changed packages:")
+		for _, line := range l.limitReport(lines) {
+			fmt.Printf("\t%s\n", line)
+		}
+	}
+
+	if l.flags.verbose && len(l.shimmed) != 0 {
+		var lines []string
+		for key, renamed := range l.shimmed {
+			lines = append(lines, fmt.Sprintf("%s: %s", key, renamed))
+		}
+		sort.Strings(lines)
+		fmt.Println("deprecated, kept exported by -deprecation-shims:")
+		for _, line := range l.limitReport(lines) {
+			fmt.Printf("\t%s\n", line)
+		}
+	}
+
+	if l.flags.verbose && len(l.skipped) != 0 {
+		var lines []string
+		for _, s := range l.skipped {
+			lines = append(lines, fmt.Sprintf("%s:%d:%d/%s: %s", s.Filename, s.Line, s.Column, s.Name, s.Reason))
+		}
+		fmt.Println("skipped:")
+		for _, line := range l.limitReport(lines) {
+			fmt.Printf("\t%s\n", line)
+		}
+	}
+
+	if len(l.testFiles) != 0 {
+		lines := make([]string, 0, len(l.testFiles))
+		for filename := range l.testFiles {
+			lines = append(lines, l.relPath(filename))
+		}
+		sort.Strings(lines)
+		fmt.Println("test files left broken by -allow-breaking-tests, they refer to unexported names of other packages now:")
+		for _, line := range l.limitReport(lines) {
+			fmt.Printf("\t%s\n", line)
 		}
 	}
+
+	if l.exportedBefore != 0 {
+		fmt.Printf("reduced exported surface by %.0f%% (%d -> %d)\n",
+			l.reductionPercent(), l.exportedBefore, l.exportedBefore-l.exportedRemoved)
+	}
 	return nil
 }
 
+// reductionPercent returns the exported symbols reduction in percents.
+func (l *linter) reductionPercent() float64 {
+	if l.exportedBefore == 0 {
+		return 0
+	}
+	return float64(l.exportedRemoved) * 100 / float64(l.exportedBefore)
+}
+
+// inTestFile reports whether sym is declared in a _test.go file.
+func (l *linter) inTestFile(sym *symbol) bool {
+	return strings.HasSuffix(l.fset.Position(sym.ident.Pos()).Filename, "_test.go")
+}
+
+// isTestFunc reports whether sym is a test file function that
+// is recognized by go test: TestMain, tests, benchmarks, examples and fuzz tests.
+func (l *linter) isTestFunc(sym *symbol) bool {
+	decl, ok := sym.decl.(*ast.FuncDecl)
+	if !ok || decl.Recv != nil || !l.inTestFile(sym) {
+		return false
+	}
+	name := sym.ident.Name
+	if name == "TestMain" {
+		return true
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		// Same as go test: Testfoo is not a test, but Test_foo is.
+		if len(name) == len(prefix) {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+		if !unicode.IsLower(r) {
+			return true
+		}
+	}
+	return false
+}
+
 func prettyError(s string) string {
 	switch {
 	case strings.Contains(s, "breaking references"):
@@ -201,15 +1354,16 @@ func prettyError(s string) string {
 		return "symbols with unexported name form already exists"
 	case strings.Contains(s, "no longer assignable to interface"):
 		return "would breaks interface assignability"
+	case strings.Contains(s, "couldn't load packages due to errors"):
+		// gorename refuses to work if any package it loads
+		// has errors, even if it's not related to the rename.
+		broken := s[strings.Index(s, "couldn't load packages due to errors"):]
+		if nl := strings.IndexByte(broken, '\n'); nl != -1 {
+			broken = broken[:nl]
+		}
+		return broken + " (fix or exclude the broken packages, or use -renamer=inprocess that only loads the targets)"
 	default:
 		fmt.Println("unknown error: ", s)
 		return "unknown error"
 	}
 }
-
-func toLowerFirst(s string) string {
-	for i, v := range s {
-		return string(unicode.ToLower(v)) + s[i+1:]
-	}
-	return ""
-}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerbFlags(t *testing.T) {
+	dir := copyFixture(t, "dead")
+	out, err := runToolErr(t, dir, "list", "-delete-dead", "./...")
+	if err == nil || !strings.Contains(out, "flag provided but not defined: -delete-dead") {
+		t.Errorf("list accepted -delete-dead:\n%s", out)
+	}
+	// The waivers don't need -renamer for the predictions.
+	if out, err := runToolErr(t, dir, "check", "-allow-breaking-tests", "-unexport", "Missing", "./..."); err != nil {
+		t.Errorf("check rejected -allow-breaking-tests: %v\n%s", err, out)
+	}
+}
+
+func TestCheckExitStatus(t *testing.T) {
+	dir := copyFixture(t, "dead")
+	out, err := runToolErr(t, dir, "check", "./...")
+	if err == nil {
+		t.Errorf("check succeeded with feasible candidates:\n%s", out)
+	}
+	wantLines(t, out, "lib/lib.go:25:6: Unused: feasible")
+
+	// Same for the cached report.
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	for i := 0; i < 2; i++ {
+		if _, err := runToolErr(t, dir, "check", "-feasibility-cache", cache, "./..."); err == nil {
+			t.Errorf("run %d: check succeeded with feasible candidates", i)
+		}
+	}
+
+	if out, err := runToolErr(t, dir, "check", "-unexport", "Missing", "./..."); err != nil {
+		t.Errorf("check failed without candidates: %v\n%s", err, out)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	dir := copyFixture(t, "dead")
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	before := readFile(t, dir, "lib/lib.go")
+
+	out := runTool(t, dir, "-renamer=inprocess", "-audit-log", auditLog, "./...")
+	wantLines(t, out, "trying to unexport Unused... (success)")
+	if readFile(t, dir, "lib/lib.go") == before {
+		t.Fatal("nothing is renamed")
+	}
+
+	out = runTool(t, dir, "undo", "-audit-log", auditLog)
+	wantLines(t, out, "reverting Unused -> unused... (success)")
+	if after := readFile(t, dir, "lib/lib.go"); after != before {
+		t.Errorf("undo didn't restore the file:\n%s", after)
+	}
+	checkBuild(t, dir)
+
+	// The reverted run is not reverted twice.
+	out, err := runToolErr(t, dir, "undo", "-audit-log", auditLog)
+	if err == nil || !strings.Contains(out, "no runs to undo") {
+		t.Errorf("the second undo didn't fail:\n%s", out)
+	}
+}
+
+func TestUndoAfterDelete(t *testing.T) {
+	// The deletions shift the positions of the renamed symbols.
+	dir := copyFixture(t, "dead")
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	runTool(t, dir, "-renamer=inprocess", "-delete-dead", "-audit-log", auditLog, "./...")
+
+	out := runTool(t, dir, "undo", "-audit-log", auditLog)
+	wantLines(t, out,
+		"reverting Use -> use... (success)",
+		"reverting Recv -> recv... (success)",
+		"reverting Unused -> <deleted>... (impossible: deleted symbols can't be restored)",
+	)
+	src := readFile(t, dir, "lib/lib.go")
+	for _, want := range []string{"func Use() {}", "var Recv = <-ch"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("lib/lib.go doesn't contain %q:\n%s", want, src)
+		}
+	}
+	checkBuild(t, dir)
+}
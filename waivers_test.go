@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// readSummary returns the renames from the dir -json-summary file.
+func readSummary(t *testing.T, dir, name string) []jsonRename {
+	t.Helper()
+	var summary jsonSummary
+	if err := json.Unmarshal([]byte(readFile(t, dir, name)), &summary); err != nil {
+		t.Fatal(err)
+	}
+	return summary.Renames
+}
+
+func TestCoordinateInternalIsBreaking(t *testing.T) {
+	dir := copyFixture(t, "waivers")
+	out := runTool(t, dir, "-renamer=inprocess", "-coordinate-internal", "-v",
+		"-json-summary", filepath.Join(dir, "summary.json"), "./internal/...", "./bar")
+	wantLines(t, out,
+		"trying to unexport Foo... (breaking: 1 references in other packages need a manual migration: ",
+		"bar/bar.go:5:27)",
+		"trying to unexport Bar... (success)",
+		"internal/x/x.go:3:6/Foo: Foo -> foo (breaking)",
+		"example.com/waivers/internal/x: 2 removed",
+		"(3 -> 0)", // Foo is unexported, even if it breaks bar
+	)
+	unwantLines(t, out, "Foo... (success)")
+
+	for _, r := range readSummary(t, dir, "summary.json") {
+		switch r.Old {
+		case "Foo":
+			if !r.Breaking || len(r.BrokenRefs) != 1 {
+				t.Errorf("Foo rename is not marked as breaking: %+v", r)
+			}
+		case "Bar":
+			if r.Breaking {
+				t.Errorf("Bar rename is marked as breaking: %+v", r)
+			}
+		}
+	}
+}
+
+func TestWithinIsBreaking(t *testing.T) {
+	dir := copyFixture(t, "waivers")
+	out := runTool(t, dir, "-renamer=inprocess", "-within", "./internal/...,./bar", "-v", "./...")
+	wantLines(t, out,
+		"trying to unexport Foo... (breaking: 1 references in other packages need a manual migration: ",
+		"trying to unexport Bar... (success)",
+		"trying to unexport Baz... (success)",
+		"example.com/waivers/internal/x: 2 removed",
+		"(4 -> 1)", // Lib is not in -within
+	)
+}
+
+func TestAllowBreakingPrefixIsBreaking(t *testing.T) {
+	dir := copyFixture(t, "waivers")
+	out := runTool(t, dir, "-renamer=inprocess", "-allow-breaking-prefix", "example.com/waivers/bar", "-v",
+		"-json-summary", filepath.Join(dir, "summary.json"), "./...")
+	wantLines(t, out,
+		"trying to unexport Foo... (breaking: 1 references in other packages need a manual migration: ",
+		"example.com/waivers/internal/x: 2 removed",
+		"(4 -> 1)",
+	)
+	for _, r := range readSummary(t, dir, "summary.json") {
+		if r.Old == "Foo" && !r.Breaking {
+			t.Errorf("Foo rename is not marked as breaking: %+v", r)
+		}
+	}
+}
+
+func TestAllowBreakingTestsIsBreaking(t *testing.T) {
+	dir := copyFixture(t, "waivers")
+	out := runTool(t, dir, "-renamer=inprocess", "-allow-breaking-tests", "-v", "./lib")
+	wantLines(t, out,
+		"trying to unexport Lib... (breaking: 1 references in other packages need a manual migration: ",
+		"example.com/waivers/lib: 1 removed",
+		"test files left broken by -allow-breaking-tests",
+		"lib/lib_test.go",
+		"(1 -> 0)",
+	)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// resolveWithin finds the package paths matched by -within patterns.
+//
+// The -within packages are the ones the user is going to update,
+// so their references don't block renames. Go can't refer to
+// an unexported name of another package though: these references
+// are left broken, and such renames are reported as breaking.
+func (l *linter) resolveWithin() error {
+	if l.flags.within == "" {
+		return nil
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName}, splitList(l.flags.within)...)
+	if err != nil {
+		return err
+	}
+	l.withinPaths = make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		l.withinPaths[pkg.PkgPath] = true
+	}
+	return nil
+}
+
+// isWithin reports whether pkgPath package is editable according to -within.
+// Without -within, all loaded packages are.
+func (l *linter) isWithin(pkgPath string) bool {
+	return l.withinPaths == nil || l.withinPaths[strings.TrimSuffix(pkgPath, "_test")]
+}
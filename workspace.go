@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadWorkspace loads all packages of the go.work modules, tests included,
+// so the references from the sibling modules are indexed too.
+// Only l.loaded is extended: the sibling packages are never processed.
+//
+// The targets packages are loaded once again here, but that's fine:
+// the references are matched by their positions, not by objects.
+func (l *linter) loadWorkspace() error {
+	out, err := exec.Command("go", "env", "GOWORK").Output()
+	if err != nil {
+		return err
+	}
+	if gowork := strings.TrimSpace(string(out)); gowork == "" || gowork == "off" {
+		return errors.New("no go.work file found")
+	}
+
+	out, err = exec.Command("go", "list", "-m", "-f", "{{.Dir}}").Output()
+	if err != nil {
+		return err
+	}
+	var patterns []string
+	for _, dir := range strings.Fields(string(out)) {
+		patterns = append(patterns, filepath.Join(dir, "..."))
+	}
+
+	cfg := &packages.Config{
+		Mode:  packages.LoadSyntax | packages.NeedModule,
+		Tests: true,
+		Fset:  l.fset,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]bool, len(l.loaded))
+	for _, pkg := range l.loaded {
+		loaded[pkg.ID] = true
+	}
+	for _, pkg := range pkgs {
+		if !loaded[pkg.ID] {
+			l.loaded = append(l.loaded, pkg)
+		}
+	}
+	return nil
+}
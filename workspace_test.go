@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runWorkspaceTool is like runTool, but the dir go.work is used.
+func runWorkspaceTool(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := goCommand(dir, toolPath, args...)
+	cmd.Env = append(cmd.Env, "GOWORK=")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go-unexport %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func TestWorkspaceTestReferences(t *testing.T) {
+	// Helper is only used by the tests of a sibling module.
+	dir := copyFixture(t, "workspace")
+	out := runWorkspaceTool(t, filepath.Join(dir, "lib"), "-renamer=inprocess", "-workspace", "./...")
+	wantLines(t, out,
+		"trying to unexport Helper... (impossible: would break package clients)",
+		"trying to unexport Other... (success)",
+	)
+
+	cmd := goCommand(filepath.Join(dir, "app"), "go", "vet", "./...")
+	cmd.Env = append(cmd.Env, "GOWORK=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("app module build failed: %v\n%s", err, out)
+	}
+
+	// The tests can be left broken on purpose.
+	dir = copyFixture(t, "workspace")
+	out = runWorkspaceTool(t, filepath.Join(dir, "lib"), "-renamer=inprocess", "-workspace", "-allow-breaking-tests", "./...")
+	wantLines(t, out,
+		"trying to unexport Helper... (breaking: 1 references in other packages need a manual migration: ",
+		"app/app_test.go:10:21)",
+	)
+}